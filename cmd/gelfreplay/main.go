@@ -0,0 +1,82 @@
+// Command gelfreplay re-sends the messages of the dead letter files written by the GELF exporters to a GELF input.
+//
+//	gelfreplay -endpoint graylog:12201 -transport tcp /var/lib/otelcol/gelf-dead-letter.log.1
+//
+// With -tls, the TCP transport connects over TLS the same way as the GELF TCP exporter,
+// and -insecure-skip-verify skips the verification of the certificate of the GELF input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelftcpexporter"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"os"
+)
+
+func main() {
+	endpoint := flag.String("endpoint", "localhost:12201", "address of the GELF input")
+	insecureSkipVerify := flag.Bool("insecure-skip-verify", false, "skip the verification of the certificate of the GELF input")
+	transport := flag.String("transport", "udp", "transport of the GELF input, \"udp\" or \"tcp\"")
+	useTLS := flag.Bool("tls", false, "connect to the GELF input over TLS, only with the \"tcp\" transport")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: gelfreplay [-endpoint address] [-transport udp|tcp] [-tls [-insecure-skip-verify]] file...")
+		os.Exit(2)
+	}
+
+	os.Exit(run(*transport, *endpoint, *useTLS, *insecureSkipVerify, flag.Args()))
+}
+
+func run(transport string, endpoint string, useTLS bool, insecureSkipVerify bool, paths []string) int {
+	writer, err := newWriter(transport, endpoint, useTLS, insecureSkipVerify)
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to %s: %s\n", endpoint, err)
+		return 1
+	}
+
+	defer writer.Close()
+
+	for _, path := range paths {
+		sent, err := replay(path, writer)
+		fmt.Printf("%s: sent %d message(s)\n", path, sent)
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func newWriter(transport string, endpoint string, useTLS bool, insecureSkipVerify bool) (gelf.Writer, error) {
+	switch {
+	case transport == "tcp" && useTLS:
+		return gelftcpexporter.NewTLSWriter(endpoint, insecureSkipVerify, zap.NewNop())
+	case transport == "tcp":
+		return gelf.NewTCPWriter(endpoint)
+	case transport == "udp" && useTLS:
+		return nil, fmt.Errorf("TLS is not supported by the %q transport", transport)
+	case transport == "udp":
+		return gelf.NewUDPWriter(endpoint)
+	}
+
+	return nil, fmt.Errorf("invalid transport %q", transport)
+}
+
+func replay(path string, writer gelf.Writer) (int, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close()
+
+	return gelfexporter.ReplayDeadLetter(file, writer)
+}
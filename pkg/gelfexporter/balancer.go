@@ -0,0 +1,382 @@
+package gelfexporter
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ringPointsPerWeight is the number of virtual nodes on the consistent hashing ring per unit of endpoint weight.
+const ringPointsPerWeight = 160
+
+// Balancer spreads messages across the connections of the configured endpoints.
+// Endpoints failing repeatedly are ejected from balancing for a while.
+type Balancer struct {
+	config *LoadBalancingConfig
+	lock   sync.Mutex
+	logger *zap.Logger
+	nodes  []*balancerNode
+	ring   []ringPoint
+}
+
+// ringPoint is a virtual node of an endpoint on the consistent hashing ring.
+type ringPoint struct {
+	hash uint64
+	node *balancerNode
+}
+
+type balancerNode struct {
+	connection    *Connection
+	currentWeight int
+	ejectedUntil  time.Time
+	failures      int
+	inflight      atomic.Int64
+	weight        int
+}
+
+func NewBalancer(cfg *Config, endpoints []EndpointConfig, dialer Dialer, logger *zap.Logger) *Balancer {
+	resolver := NewResolver(cfg)
+
+	b := &Balancer{
+		config: &cfg.LoadBalancing,
+		logger: logger,
+		nodes:  make([]*balancerNode, 0, len(endpoints)),
+	}
+
+	// A weight of 0 is an omitted weight, which defaults to 1.
+	for _, endpoint := range endpoints {
+		b.nodes = append(b.nodes, &balancerNode{
+			connection: NewConnection(endpoint.Endpoint, cfg, resolver, dialer, logger),
+			weight:     max(endpoint.Weight, 1),
+		})
+	}
+
+	if cfg.RoutingKey.Attribute != "" {
+		b.ring = newRing(b.nodes)
+	}
+
+	return b
+}
+
+// newRing places ringPointsPerWeight virtual nodes per unit of weight of each endpoint on the ring,
+// so that adding or removing an endpoint only moves the keys of its own share.
+func newRing(nodes []*balancerNode) []ringPoint {
+	var ring []ringPoint
+
+	for _, node := range nodes {
+		for i := 0; i < node.weight*ringPointsPerWeight; i++ {
+			ring = append(ring, ringPoint{
+				hash: hashKey(fmt.Sprintf("%s#%d", node.connection.Endpoint(), i)),
+				node: node,
+			})
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool {
+		return ring[i].hash < ring[j].hash
+	})
+
+	return ring
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+// String returns the endpoints of the balancer.
+func (b *Balancer) String() string {
+	endpoints := make([]string, 0, len(b.nodes))
+
+	for _, node := range b.nodes {
+		endpoints = append(endpoints, node.connection.Endpoint())
+	}
+
+	return strings.Join(endpoints, ",")
+}
+
+// Init initializes the connections of all endpoints and ejects the ones that failed.
+// It reports whether at least one endpoint is available.
+func (b *Balancer) Init() bool {
+	var wg sync.WaitGroup
+
+	initialized := make([]bool, len(b.nodes))
+
+	for i, node := range b.nodes {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			initialized[i] = node.connection.Init()
+		}()
+	}
+
+	wg.Wait()
+
+	return b.reportInitResults(initialized)
+}
+
+// Probe makes a single attempt to establish fresh connections to all endpoints.
+// It reports whether at least one endpoint is available.
+func (b *Balancer) Probe() bool {
+	probed := make([]bool, len(b.nodes))
+
+	for i, node := range b.nodes {
+		probed[i] = node.connection.Reconnect()
+	}
+
+	return b.reportInitResults(probed)
+}
+
+// Refresh refreshes the connections of the available endpoints and ejects the ones that failed.
+// It reports false only if all refreshed endpoints failed.
+func (b *Balancer) Refresh() bool {
+	var attempted bool
+
+	refreshed := make([]bool, len(b.nodes))
+
+	for i, node := range b.nodes {
+		if b.isEjected(node) {
+			continue
+		}
+
+		attempted = true
+		refreshed[i] = node.connection.Refresh()
+	}
+
+	return b.reportInitResults(refreshed) || !attempted
+}
+
+// WriteMessage writes the message to a picked endpoint, trying the other ones if it fails.
+// With a routing key configured, messages with the same non-empty key are written to the same endpoint.
+func (b *Balancer) WriteMessage(m *gelf.Message, key string) error {
+	var errs []error
+
+	tried := make(map[*balancerNode]bool, len(b.nodes))
+
+	for node := b.pick(tried, key); node != nil; node = b.pick(tried, key) {
+		tried[node] = true
+
+		node.inflight.Add(1)
+		err := node.connection.WriteMessage(m)
+		node.inflight.Add(-1)
+
+		if err == nil {
+			b.reportSuccess(node)
+			return nil
+		}
+
+		b.reportFailure(node, err)
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes the connections of all endpoints.
+func (b *Balancer) Close() error {
+	var errs []error
+
+	for _, node := range b.nodes {
+		if err := node.connection.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (b *Balancer) reportInitResults(results []bool) bool {
+	var available bool
+
+	for i, node := range b.nodes {
+		if results[i] {
+			b.reportSuccess(node)
+			available = true
+		} else if !b.isEjected(node) {
+			b.eject(node)
+		}
+	}
+
+	return available
+}
+
+func (b *Balancer) reportSuccess(node *balancerNode) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	node.failures = 0
+
+	if !node.ejectedUntil.IsZero() {
+		b.logger.Info(fmt.Sprintf("endpoint %s is healthy again", node.connection.Endpoint()))
+		node.ejectedUntil = time.Time{}
+	}
+}
+
+func (b *Balancer) reportFailure(node *balancerNode, err error) {
+	b.lock.Lock()
+	node.failures++
+	failures := node.failures
+	b.lock.Unlock()
+
+	b.logger.Warn(fmt.Sprintf("failed to write message to endpoint %s", node.connection.Endpoint()), zap.Error(err))
+
+	if failures >= b.config.EjectionThreshold {
+		b.eject(node)
+	}
+}
+
+func (b *Balancer) eject(node *balancerNode) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	node.ejectedUntil = time.Now().Add(time.Duration(b.config.EjectionDuration) * time.Second)
+
+	b.logger.Warn(fmt.Sprintf("ejecting endpoint %s for %ds", node.connection.Endpoint(), b.config.EjectionDuration))
+}
+
+func (b *Balancer) isEjected(node *balancerNode) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return time.Now().Before(node.ejectedUntil)
+}
+
+// pick returns the next endpoint not tried yet according to the load balancing strategy.
+// If all remaining endpoints are ejected, the one whose ejection ends first is returned.
+func (b *Balancer) pick(tried map[*balancerNode]bool, key string) *balancerNode {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if key != "" && len(b.ring) > 0 {
+		return b.pickConsistentHash(tried, key)
+	}
+
+	var candidates []*balancerNode
+	var fallback *balancerNode
+
+	now := time.Now()
+
+	for _, node := range b.nodes {
+		if tried[node] {
+			continue
+		}
+
+		if now.Before(node.ejectedUntil) {
+			if fallback == nil || node.ejectedUntil.Before(fallback.ejectedUntil) {
+				fallback = node
+			}
+
+			continue
+		}
+
+		candidates = append(candidates, node)
+	}
+
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	switch b.config.Strategy {
+	case LoadBalancingRandom:
+		return pickRandom(candidates)
+	case LoadBalancingLeastInflight:
+		return pickLeastInflight(candidates)
+	}
+
+	return pickRoundRobin(candidates)
+}
+
+// pickConsistentHash returns the first endpoint clockwise from the key on the ring that was not tried yet,
+// skipping ejected endpoints unless all remaining ones are ejected.
+func (b *Balancer) pickConsistentHash(tried map[*balancerNode]bool, key string) *balancerNode {
+	var fallback *balancerNode
+
+	now := time.Now()
+	hash := hashKey(key)
+	start := sort.Search(len(b.ring), func(i int) bool {
+		return b.ring[i].hash >= hash
+	})
+
+	for i := 0; i < len(b.ring); i++ {
+		node := b.ring[(start+i)%len(b.ring)].node
+
+		if tried[node] {
+			continue
+		}
+
+		if !now.Before(node.ejectedUntil) {
+			return node
+		}
+
+		if fallback == nil {
+			fallback = node
+		}
+	}
+
+	return fallback
+}
+
+// pickRoundRobin implements the smooth weighted round-robin balancing.
+func pickRoundRobin(candidates []*balancerNode) *balancerNode {
+	var total int
+	var best *balancerNode
+
+	for _, node := range candidates {
+		node.currentWeight += node.weight
+		total += node.weight
+
+		if best == nil || node.currentWeight > best.currentWeight {
+			best = node
+		}
+	}
+
+	best.currentWeight -= total
+
+	return best
+}
+
+func pickRandom(candidates []*balancerNode) *balancerNode {
+	var total int
+
+	for _, node := range candidates {
+		total += node.weight
+	}
+
+	n := rand.IntN(total)
+
+	for _, node := range candidates {
+		if n -= node.weight; n < 0 {
+			return node
+		}
+	}
+
+	return candidates[len(candidates)-1]
+}
+
+func pickLeastInflight(candidates []*balancerNode) *balancerNode {
+	var best *balancerNode
+	var bestInflight int64
+
+	for _, node := range candidates {
+		inflight := node.inflight.Load()
+
+		// inflight / weight < bestInflight / best.weight
+		if best == nil || inflight*int64(best.weight) < bestInflight*int64(node.weight) {
+			best = node
+			bestInflight = inflight
+		}
+	}
+
+	return best
+}
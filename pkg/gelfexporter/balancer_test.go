@@ -0,0 +1,160 @@
+package gelfexporter
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"testing"
+)
+
+func newTestBalancerConfig(strategy string, endpoints ...EndpointConfig) *Config {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoints = endpoints
+	cfg.LoadBalancing.Strategy = strategy
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointInitRetries = 1
+
+	return cfg
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	dialer, messages := testDialer()
+
+	// The weight of the second endpoint is omitted, so it defaults to 1.
+	cfg := newTestBalancerConfig(LoadBalancingRoundRobin,
+		EndpointConfig{Endpoint: "10.0.0.1:12201", Weight: 2},
+		EndpointConfig{Endpoint: "10.0.0.2:12201"},
+	)
+
+	b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+	require.True(t, b.Init())
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, b.WriteMessage(&gelf.Message{}, ""))
+	}
+
+	assert.Equal(t, []string{
+		"10.0.0.1:12201", "10.0.0.2:12201", "10.0.0.1:12201",
+		"10.0.0.1:12201", "10.0.0.2:12201", "10.0.0.1:12201",
+	}, messages.addresses())
+}
+
+func TestBalancerRandom(t *testing.T) {
+	dialer, messages := testDialer()
+	cfg := newTestBalancerConfig(LoadBalancingRandom,
+		EndpointConfig{Endpoint: "10.0.0.1:12201", Weight: 3},
+		EndpointConfig{Endpoint: "10.0.0.2:12201"},
+	)
+
+	b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+	require.True(t, b.Init())
+
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, b.WriteMessage(&gelf.Message{}, ""))
+	}
+
+	var first int
+
+	for _, address := range messages.addresses() {
+		if address == "10.0.0.1:12201" {
+			first++
+		}
+	}
+
+	assert.InDelta(t, 750, first, 100)
+}
+
+func TestBalancerLeastInflight(t *testing.T) {
+	dialer, messages := testDialer()
+	cfg := newTestBalancerConfig(LoadBalancingLeastInflight,
+		EndpointConfig{Endpoint: "10.0.0.1:12201"},
+		EndpointConfig{Endpoint: "10.0.0.2:12201"},
+	)
+
+	b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+	require.True(t, b.Init())
+
+	b.nodes[0].inflight.Add(1)
+	require.NoError(t, b.WriteMessage(&gelf.Message{}, ""))
+	b.nodes[0].inflight.Add(-1)
+
+	assert.Equal(t, []string{"10.0.0.2:12201"}, messages.addresses())
+}
+
+func TestBalancerEjection(t *testing.T) {
+	dialer, messages := testDialer("10.0.0.1:12201")
+	cfg := newTestBalancerConfig(LoadBalancingRoundRobin,
+		EndpointConfig{Endpoint: "10.0.0.1:12201"},
+		EndpointConfig{Endpoint: "10.0.0.2:12201"},
+	)
+	cfg.LoadBalancing.EjectionThreshold = 2
+
+	b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+	require.True(t, b.Init())
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, b.WriteMessage(&gelf.Message{}, ""))
+	}
+
+	assert.Len(t, messages.addresses(), 6)
+	assert.True(t, b.isEjected(b.nodes[0]))
+	assert.False(t, b.isEjected(b.nodes[1]))
+}
+
+func TestBalancerAllEndpointsFailing(t *testing.T) {
+	dialer, _ := testDialer("10.0.0.1:12201", "10.0.0.2:12201")
+	cfg := newTestBalancerConfig(LoadBalancingRoundRobin,
+		EndpointConfig{Endpoint: "10.0.0.1:12201"},
+		EndpointConfig{Endpoint: "10.0.0.2:12201"},
+	)
+
+	b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+	require.True(t, b.Init())
+
+	assert.EqualError(t, b.WriteMessage(&gelf.Message{}, ""), "connection refused\nconnection refused")
+}
+
+func TestBalancerConsistentHash(t *testing.T) {
+	endpoints := []EndpointConfig{
+		{Endpoint: "10.0.0.1:12201"},
+		{Endpoint: "10.0.0.2:12201"},
+		{Endpoint: "10.0.0.3:12201"},
+	}
+
+	route := func(endpoints []EndpointConfig) map[string]string {
+		dialer, messages := testDialer()
+		cfg := newTestBalancerConfig(LoadBalancingRoundRobin, endpoints...)
+		cfg.RoutingKey.Attribute = "service.name"
+
+		b := NewBalancer(cfg, cfg.Endpoints, dialer, zap.NewNop())
+		require.True(t, b.Init())
+
+		routes := make(map[string]string)
+
+		for i := 0; i < 300; i++ {
+			key := fmt.Sprintf("service-%d", i)
+
+			require.NoError(t, b.WriteMessage(&gelf.Message{}, key))
+			require.NoError(t, b.WriteMessage(&gelf.Message{}, key))
+
+			addresses := messages.addresses()
+			last := addresses[len(addresses)-2:]
+			require.Equal(t, last[0], last[1])
+
+			routes[key] = last[0]
+		}
+
+		return routes
+	}
+
+	before := route(endpoints)
+	after := route(endpoints[:2])
+
+	for key, endpoint := range before {
+		if endpoint != "10.0.0.3:12201" {
+			assert.Equal(t, endpoint, after[key], key)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package gelfexporter
+
+import (
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	CircuitStateClosed   string = "closed"
+	CircuitStateHalfOpen string = "half_open"
+	CircuitStateOpen     string = "open"
+)
+
+// CircuitBreaker rejects the batches of a route while its endpoints are down, instead of resolving and dialing them
+// for every batch. The circuit opens after FailureThreshold batches failed in a row, and after CoolDown it lets
+// a single trial batch through at a time, closing again after SuccessThreshold trial batches succeeded.
+// All methods are no-ops on a nil CircuitBreaker, which never rejects batches.
+type CircuitBreaker struct {
+	config        *CircuitBreakerConfig
+	failures      int
+	lock          sync.Mutex
+	logger        *zap.Logger
+	onStateChange func(state string)
+	openedAt      time.Time
+	route         string
+	state         string
+	successes     int
+	trial         bool
+}
+
+func NewCircuitBreaker(cfg *CircuitBreakerConfig, route string, onStateChange func(state string), logger *zap.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		config:        cfg,
+		logger:        logger,
+		onStateChange: onStateChange,
+		route:         route,
+		state:         CircuitStateClosed,
+	}
+}
+
+// State returns the current state of the circuit.
+func (b *CircuitBreaker) State() string {
+	if b == nil {
+		return CircuitStateClosed
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state
+}
+
+// Allow returns a CircuitOpenError if the batch is rejected, switching the open circuit to half-open
+// once CoolDown has passed. The result of an allowed batch has to be reported with Report.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == CircuitStateOpen && time.Since(b.openedAt) >= b.config.CoolDown {
+		b.transition(CircuitStateHalfOpen)
+	}
+
+	switch {
+	case b.state == CircuitStateOpen:
+		return &CircuitOpenError{Route: b.route, RetryAfter: b.config.CoolDown - time.Since(b.openedAt)}
+	case b.state == CircuitStateHalfOpen && b.trial:
+		return &CircuitOpenError{Route: b.route}
+	case b.state == CircuitStateHalfOpen:
+		b.trial = true
+	}
+
+	return nil
+}
+
+// isLimitError reports whether the error is caused by the limits of the exporter.
+func isLimitError(err error) bool {
+	return errors.As(err, new(*RateLimitError)) || errors.As(err, new(*InflightLimitError))
+}
+
+// Report records the result of an allowed batch. Permanent errors are caused by the batch rather than
+// by the endpoints, and a RateLimitError or an InflightLimitError by the exporter itself,
+// so they neither open nor close the circuit.
+func (b *CircuitBreaker) Report(err error) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.trial = false
+
+	switch {
+	case err != nil && (consumererror.IsPermanent(err) || isLimitError(err)):
+		return
+	case err != nil && b.state == CircuitStateHalfOpen:
+		b.open()
+	case err != nil:
+		if b.failures++; b.failures >= b.config.FailureThreshold {
+			b.open()
+		}
+	case b.state == CircuitStateHalfOpen:
+		if b.successes++; b.successes >= b.config.SuccessThreshold {
+			b.transition(CircuitStateClosed)
+		}
+	default:
+		b.failures = 0
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.openedAt = time.Now()
+	b.transition(CircuitStateOpen)
+}
+
+func (b *CircuitBreaker) transition(state string) {
+	if state == b.state {
+		return
+	}
+
+	switch state {
+	case CircuitStateOpen:
+		b.logger.Warn(fmt.Sprintf("opened circuit of route %s, rejecting batches for %s", b.route, b.config.CoolDown))
+	case CircuitStateHalfOpen:
+		b.logger.Info(fmt.Sprintf("circuit of route %s is half-open, trying the next batch", b.route))
+	case CircuitStateClosed:
+		b.logger.Info(fmt.Sprintf("closed circuit of route %s", b.route))
+	}
+
+	b.failures = 0
+	b.state = state
+	b.successes = 0
+
+	if b.onStateChange != nil {
+		b.onStateChange(state)
+	}
+}
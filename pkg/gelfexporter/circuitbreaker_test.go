@@ -0,0 +1,114 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func newTestCircuitBreaker(states *[]string) *CircuitBreaker {
+	cfg := &CircuitBreakerConfig{
+		CoolDown:         50 * time.Millisecond,
+		Enabled:          true,
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+	}
+
+	return NewCircuitBreaker(cfg, DefaultRouteName, func(state string) {
+		*states = append(*states, state)
+	}, zap.NewNop())
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var states []string
+
+	b := newTestCircuitBreaker(&states)
+	failure := errors.New("connection refused")
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Report(failure)
+	}
+
+	var openErr *CircuitOpenError
+
+	require.ErrorAs(t, b.Allow(), &openErr)
+	assert.Equal(t, DefaultRouteName, openErr.Route)
+	assert.False(t, consumererror.IsPermanent(openErr))
+
+	time.Sleep(50 * time.Millisecond)
+
+	// A single trial batch is let through while the circuit is half-open.
+	require.NoError(t, b.Allow())
+	require.Error(t, b.Allow())
+	b.Report(nil)
+
+	require.NoError(t, b.Allow())
+	b.Report(nil)
+
+	assert.Equal(t, CircuitStateClosed, b.State())
+	assert.Equal(t, []string{CircuitStateOpen, CircuitStateHalfOpen, CircuitStateClosed}, states)
+}
+
+func TestCircuitBreakerTrialFailure(t *testing.T) {
+	var states []string
+
+	b := newTestCircuitBreaker(&states)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Report(errors.New("connection refused"))
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	require.NoError(t, b.Allow())
+	b.Report(errors.New("connection refused"))
+
+	assert.Equal(t, CircuitStateOpen, b.State())
+	assert.Error(t, b.Allow())
+}
+
+func TestCircuitBreakerIgnoresPermanentErrors(t *testing.T) {
+	var states []string
+
+	b := newTestCircuitBreaker(&states)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, b.Allow())
+		b.Report(consumererror.NewPermanent(errors.New("msg too large")))
+	}
+
+	assert.Equal(t, CircuitStateClosed, b.State())
+	assert.Empty(t, states)
+}
+
+func TestCircuitBreakerIgnoresLimitErrors(t *testing.T) {
+	var states []string
+
+	b := newTestCircuitBreaker(&states)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, b.Allow())
+		b.Report(&InflightLimitError{Limit: 1024})
+		require.NoError(t, b.Allow())
+		b.Report(&RateLimitError{Err: context.DeadlineExceeded})
+	}
+
+	assert.Equal(t, CircuitStateClosed, b.State())
+	assert.Empty(t, states)
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	var b *CircuitBreaker
+
+	b.Report(errors.New("connection refused"))
+
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, CircuitStateClosed, b.State())
+}
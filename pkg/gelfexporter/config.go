@@ -2,29 +2,119 @@ package gelfexporter
 
 import (
 	"errors"
+	"fmt"
+	"github.com/cenkalti/backoff/v5"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.uber.org/zap"
+	"net"
+	"strings"
+	"time"
 )
 
 const (
-	DefaultEndpointInitBackoff        int    = 10
+	ConnectOnStartBackground          string = "background"
+	ConnectOnStartBlocking            string = "blocking"
+	ConnectOnStartLazy                string = "lazy"
+	DefaultCircuitBreakerCoolDown            = 30 * time.Second
+	DefaultCircuitBreakerFailures     int    = 5
+	DefaultCircuitBreakerSuccesses    int    = 1
+	DefaultDeadLetterMaxBackups       int    = 5
+	DefaultDeadLetterMaxSize          int64  = 100 * 1024 * 1024
+	DefaultEjectionDuration           int64  = 30
+	DefaultEjectionThreshold          int    = 3
+	DefaultEndpointBackoffInitial            = time.Second
+	DefaultEndpointBackoffJitter             = 0.5
+	DefaultEndpointBackoffMaxElapsed         = time.Minute
+	DefaultEndpointBackoffMaxInterval        = 30 * time.Second
+	DefaultEndpointBackoffMultiplier         = 1.5
+	DefaultEndpointInitBackoff        int    = 0
 	DefaultEndpointInitRetries        int    = 5
 	DefaultEndpointRefreshInterval    int64  = 60
-	EndpointRefreshStrategyNone       string = "none"
+	DefaultEndpointRefreshMaxTTL      int64  = 300
+	DefaultEndpointRefreshMinTTL      int64  = 5
+	DefaultFailoverProbeInterval      int64  = 30
+	DefaultFailoverWriteFailures      int    = 3
+	DefaultMirrorQueueSize            int    = 1000
+	DefaultPriorityShedDebug                 = 0.5
+	DefaultPriorityShedInfo                  = 0.75
+	DefaultPriorityShedWarn                  = 0.9
+	DefaultQueueNumConsumers          int    = 2
+	DefaultQueueSize                  int    = 5000
+	DefaultRateLimitMinSeverity       string = "WARN"
+	DefaultResolverTimeout                   = 5 * time.Second
+	DefaultRetryInitialInterval              = time.Second
+	DefaultRetryMaxElapsedTime               = 5 * time.Minute
+	DefaultRetryMaxInterval                  = 30 * time.Second
+	DefaultShutdownTimeout                   = 10 * time.Second
+	DefaultTimeout                           = 10 * time.Second
+	EndpointRefreshStrategyDNSTTL     string = "dns_ttl"
 	EndpointRefreshStrategyInterval   string = "interval"
+	EndpointRefreshStrategyNone       string = "none"
 	EndpointRefreshStrategyPerMessage string = "perMessage"
+	IPFamilyAny                       string = "any"
+	IPFamilyIPv4                      string = "ipv4"
+	IPFamilyIPv6                      string = "ipv6"
+	IPFamilyPreferIPv4                string = "prefer_ipv4"
+	IPFamilyPreferIPv6                string = "prefer_ipv6"
+	LoadBalancingLeastInflight        string = "least_inflight"
+	LoadBalancingRandom               string = "random"
+	LoadBalancingRoundRobin           string = "round_robin"
+	RateLimitActionBlock              string = "block"
+	RateLimitActionDowngrade          string = "downgrade"
+	RateLimitActionDrop               string = "drop"
+	ResolverProtocolDoT               string = "dot"
+	ResolverProtocolTCP               string = "tcp"
+	ResolverProtocolUDP               string = "udp"
+	RoutingKeySourceLog               string = "log"
+	RoutingKeySourceResource          string = "resource"
 	TcpExporterType                   string = "gelftcp"
 	UdpExporterType                   string = "gelfudp"
 )
 
 type Config struct {
+	// CircuitBreaker is a configuration of rejecting batches of a route immediately while its endpoints are down.
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+
+	// ConnectOnStart is the way the endpoints are connected when the exporter starts.
+	// Possible values are "blocking", "background" and "lazy".
+	// Default value is "blocking".
+	// "blocking" means that the exporter fails to start if the endpoints can't be initialized.
+	// "background" means that the exporter starts immediately and initializes the endpoints in the background.
+	// "lazy" means that the endpoints are initialized in the background once the first logs are exported.
+	// Until the endpoints are initialized, exports fail with a retryable error.
+	ConnectOnStart string `mapstructure:"connect_on_start"`
+
+	// DeadLetter is a configuration of the file the messages that can't be delivered are written to,
+	// such as messages failing permanently and batches whose retries are exhausted.
+	DeadLetter DeadLetterConfig `mapstructure:"dead_letter"`
+
 	// Endpoint is the address of the GELF input.
+	// Endpoints in the form "srv://_gelf._tcp.example.com" are discovered through DNS SRV records
+	// and re-queried according to EndpointRefreshStrategy.
 	Endpoint string `mapstructure:"endpoint"`
 
-	// EndpointInitBackoff is a delay between retries to initialize the endpoint.
-	// Default is 10.
+	// Endpoints is a list of GELF inputs the messages are balanced across.
+	// It is mutually exclusive with Endpoint.
+	Endpoints []EndpointConfig `mapstructure:"endpoints"`
+
+	// EndpointBackoff is a configuration of the exponential backoff between retries to initialize
+	// or reconnect the endpoint. The randomization spreads reconnecting collectors over time
+	// once a GELF input is available again.
+	// Default is 1s initially, growing 1.5 times up to 30s, randomized by 0.5, for at most 1m in total.
+	// Disabling it means that the endpoint is initialized in a single attempt.
+	EndpointBackoff configretry.BackOffConfig `mapstructure:"endpoint_backoff"`
+
+	// EndpointInitBackoff is a fixed delay in seconds between retries to initialize the endpoint.
+	// If set, it replaces the exponential backoff of EndpointBackoff.
+	// Default is 0, which means that EndpointBackoff is used. It was 10 before EndpointBackoff was added.
+	//
+	// Deprecated: use EndpointBackoff instead.
 	EndpointInitBackoff int `mapstructure:"endpoint_init_backoff"`
 
 	// EndpointInitRetries is a number of retries to initialize the endpoint.
+	// The retries also stop once EndpointBackoff.MaxElapsedTime is exceeded.
 	// Default is 5.
 	EndpointInitRetries int `mapstructure:"endpoint_init_retries"`
 
@@ -33,36 +123,841 @@ type Config struct {
 	// This is only used when EndpointRefreshStrategy is set to "interval".
 	EndpointRefreshInterval int64 `mapstructure:"endpoint_refresh_interval"`
 
+	// EndpointRefreshMaxTTL is the maximum time in seconds the resolved endpoint is cached for.
+	// Default value is 300.
+	// This is only used when EndpointRefreshStrategy is set to "dns_ttl".
+	EndpointRefreshMaxTTL int64 `mapstructure:"endpoint_refresh_max_ttl"`
+
+	// EndpointRefreshMinTTL is the minimum time in seconds the resolved endpoint is cached for.
+	// Default value is 5.
+	// This is only used when EndpointRefreshStrategy is set to "dns_ttl".
+	EndpointRefreshMinTTL int64 `mapstructure:"endpoint_refresh_min_ttl"`
+
 	// EndpointRefreshStrategy is the strategy used to refresh the endpoint.
-	// Possible values are "none", "interval" and "perMessage".
+	// Possible values are "none", "interval", "dns_ttl" and "perMessage".
 	// Default value is "none".
 	// "none" means that the endpoint is not refreshed.
 	// "interval" means that the endpoint is refreshed every EndpointRefreshInterval seconds.
+	// "dns_ttl" means that the endpoint is refreshed when the TTL of its DNS records expires,
+	// clamped to EndpointRefreshMinTTL and EndpointRefreshMaxTTL.
 	// "perMessage" means that the endpoint is refreshed for every log message.
 	EndpointRefreshStrategy string `mapstructure:"endpoint_refresh_strategy"`
+
+	// Failover is a configuration of failing over to FailoverEndpoints.
+	Failover FailoverConfig `mapstructure:"failover"`
+
+	// FailoverEndpoints is an ordered list of GELF inputs used when the primary endpoints fail.
+	// Default is empty, which means that there is no failover.
+	FailoverEndpoints []string `mapstructure:"failover_endpoints"`
+
+	// IPFamily is the address family used when connecting to the resolved endpoint.
+	// Possible values are "any", "ipv4", "ipv6", "prefer_ipv4" and "prefer_ipv6".
+	// Default value is "any".
+	// "any" interleaves IPv6 and IPv4 addresses, starting with the family of the first resolved address.
+	// "ipv4" and "ipv6" only use addresses of the given family.
+	// "prefer_ipv4" and "prefer_ipv6" try addresses of the given family first and fall back to the other one.
+	IPFamily string `mapstructure:"ip_family"`
+
+	// LoadBalancing is a configuration of balancing the messages across Endpoints.
+	LoadBalancing LoadBalancingConfig `mapstructure:"load_balancing"`
+
+	// MaxInflightBytes is the limit of the memory taken by the log records being converted and sent at once
+	// by all consumers of the sending queue. The limit is compared to the size of the protobuf encoding
+	// of the log records, not to the size of the GELF messages they are converted to, which usually differs.
+	// Batches are then converted and sent in chunks of log records, and a batch whose next chunk exceeds
+	// the limit is refused with a retryable error, leaving its unsent log records to the retries.
+	// A chunk larger than the limit is only sent while nothing else is in flight.
+	// Default is 0, which means that batches are converted at once without a limit.
+	MaxInflightBytes int64 `mapstructure:"max_inflight_bytes"`
+
+	// Mirrors is a list of additional destinations receiving a copy of the messages written to the endpoints.
+	// Mirrors are written to in the background, so that a failing mirror never fails the export.
+	// Default is empty, which means that logs are not mirrored.
+	Mirrors []MirrorConfig `mapstructure:"mirrors"`
+
+	// Priority is a configuration of sending log records in order of severity
+	// and shedding the lower severities first as the sending queue fills.
+	Priority PriorityConfig `mapstructure:"priority"`
+
+	// QueueConfig is a configuration of the queue of batches waiting to be sent.
+	// Setting storage to the ID of a storage extension (e.g. file_storage) persists the queue across restarts.
+	QueueConfig exporterhelper.QueueConfig `mapstructure:"sending_queue"`
+
+	// RateLimit is a configuration of limiting the rate of messages written by the exporter,
+	// in total and per value of a resource attribute.
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+
+	// Resolver is a configuration of the DNS resolver used to resolve the endpoint.
+	Resolver ResolverConfig `mapstructure:"resolver"`
+
+	// RetryConfig is a configuration of retrying batches that failed to be sent.
+	RetryConfig configretry.BackOffConfig `mapstructure:"retry_on_failure"`
+
+	// Routes is an ordered list of routes sending matching logs to their own endpoints.
+	// Logs are sent to the first matching route, and logs matching no route are sent
+	// to the endpoints of the exporter, which form the default route.
+	// Default is empty, which means that all logs are sent to the default route.
+	Routes []RouteConfig `mapstructure:"routes"`
+
+	// RoutingKey is a configuration of the attribute used to route related logs to the same endpoint.
+	RoutingKey RoutingKeyConfig `mapstructure:"routing_key"`
+
+	// ShutdownTimeout is the deadline for writing the messages still queued by the mirrors
+	// and closing the connections when the exporter shuts down. Connections still dialing or writing once it is
+	// exceeded are closed in the background.
+	// Batches in the sending queue are drained before, each within Timeout.
+	// Default is 10s, and 0 means that there is no deadline.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+
+	// TimeoutConfig is a configuration of the timeout of sending a batch.
+	TimeoutConfig exporterhelper.TimeoutConfig `mapstructure:",squash"`
+}
+
+type CircuitBreakerConfig struct {
+	// CoolDown is the time the circuit stays open before trial batches are let through.
+	// Default is 30s.
+	CoolDown time.Duration `mapstructure:"cool_down"`
+
+	// Enabled is a flag that enables or disables the circuit breaker.
+	// While the circuit is open, batches are rejected with a retryable error, delaying their retry until CoolDown ends.
+	// Default is false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// FailureThreshold is the number of batches of a route failing in a row that opens its circuit.
+	// Default is 5.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+
+	// SuccessThreshold is the number of trial batches succeeding in a row that closes the half-open circuit.
+	// Default is 1.
+	SuccessThreshold int `mapstructure:"success_threshold"`
+}
+
+type DeadLetterConfig struct {
+	// Enabled is a flag that enables or disables the dead letter file.
+	// Default is false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// MaxBackups is the number of rotated files kept, named after Path with a numeric suffix, ".1" being the newest.
+	// Default is 5, and 0 means that the file is discarded when it is rotated.
+	MaxBackups int `mapstructure:"max_backups"`
+
+	// MaxSize is the size in bytes the file is rotated at.
+	// Default is 100 MiB.
+	MaxSize int64 `mapstructure:"max_size"`
+
+	// Path is the path of the file. Each line is a JSON object with the rendered GELF message,
+	// the reason it could not be delivered and the time of the failure.
+	Path string `mapstructure:"path"`
+}
+
+type EndpointConfig struct {
+	// Endpoint is the address of the GELF input.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Weight is the relative share of messages sent to the endpoint.
+	// Default is 1, which is also used for 0, as an omitted weight can't be told apart from 0.
+	// An endpoint can't be excluded with a weight of 0, it has to be removed instead.
+	Weight int `mapstructure:"weight"`
+}
+
+type FailoverConfig struct {
+	// ProbeInterval is the interval in seconds between checks whether the primary endpoints are available again.
+	// Default value is 30.
+	ProbeInterval int64 `mapstructure:"probe_interval"`
+
+	// WriteFailureThreshold is the number of consecutive write failures after which the next endpoints are used.
+	// Default value is 3.
+	WriteFailureThreshold int `mapstructure:"write_failure_threshold"`
+}
+
+type LoadBalancingConfig struct {
+	// EjectionDuration is the time in seconds an unhealthy endpoint is excluded from balancing.
+	// Default value is 30.
+	EjectionDuration int64 `mapstructure:"ejection_duration"`
+
+	// EjectionThreshold is the number of consecutive failures after which an endpoint is ejected.
+	// Default value is 3.
+	EjectionThreshold int `mapstructure:"ejection_threshold"`
+
+	// Strategy is the strategy used to pick the endpoint for a message.
+	// Possible values are "round_robin", "random" and "least_inflight".
+	// Default value is "round_robin".
+	// "round_robin" cycles through the endpoints in proportion to their weights.
+	// "random" picks a random endpoint with probability proportional to its weight.
+	// "least_inflight" picks the endpoint with the fewest messages being written relative to its weight.
+	Strategy string `mapstructure:"strategy"`
+}
+
+type MirrorConfig struct {
+	// Endpoint is the address of the GELF input of the mirror.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Endpoints is a list of GELF inputs of the mirror the messages are balanced across.
+	// It is mutually exclusive with Endpoint.
+	Endpoints []EndpointConfig `mapstructure:"endpoints"`
+
+	// EndpointTLS overrides the TLS settings of the exporter for the endpoints of the mirror.
+	// It is only used by the gelftcp exporter.
+	EndpointTLS RouteEndpointTLS `mapstructure:"endpoint_tls"`
+
+	// FailoverEndpoints is an ordered list of GELF inputs of the mirror used when its endpoints fail.
+	FailoverEndpoints []string `mapstructure:"failover_endpoints"`
+
+	// Name is the unique name of the mirror.
+	Name string `mapstructure:"name"`
+
+	// QueueSize is the number of messages waiting to be written to the mirror.
+	// Messages are dropped while the queue is full.
+	// Default value is 1000.
+	QueueSize int `mapstructure:"queue_size"`
+
+	// SamplingRatio is the share of messages written to the mirror, between 0 and 1.
+	// Default value is 1, which means that all messages are mirrored.
+	SamplingRatio *float64 `mapstructure:"sampling_ratio"`
+}
+
+type PriorityConfig struct {
+	// Enabled is a flag that enables or disables the priority mode.
+	// The log records of each batch are sent from the highest severity level to the lowest,
+	// and before a batch is queued, the records of the levels whose ShedThresholds are reached are dropped.
+	// Records without a severity are treated as "info", and "error" and "fatal" records are never shed.
+	// The fill of the sending queue is the number of batches it holds out of its queue_size, which counts
+	// batches as the sending queue doesn't support other sizers. Without the sending queue, records are only ordered.
+	// Default is false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// ShedThresholds are the fill ratios of the sending queue at which the records of each level are shed.
+	ShedThresholds PriorityShedThresholds `mapstructure:"shed_thresholds"`
+}
+
+type PriorityShedThresholds struct {
+	// Debug is the fill ratio "trace" and "debug" records are shed at.
+	// Default is 0.5.
+	Debug float64 `mapstructure:"debug"`
+
+	// Info is the fill ratio "info" records are shed at.
+	// Default is 0.75.
+	Info float64 `mapstructure:"info"`
+
+	// Warn is the fill ratio "warn" records are shed at.
+	// Default is 0.9.
+	Warn float64 `mapstructure:"warn"`
+}
+
+type RateLimitConfig struct {
+	// Action is what happens to messages over the limit.
+	// Possible values are "block", "drop" and "downgrade".
+	// Default value is "drop".
+	// "block" means that writing waits until the limit allows the message, at most until the batch times out.
+	// "drop" means that the message is dropped.
+	// "downgrade" means that the message is dropped unless its severity is at least MinSeverity.
+	Action string `mapstructure:"action"`
+
+	// BytesPerSecond is the limit of bytes of GELF messages written per second in total.
+	// Default is 0, which means that bytes are not limited.
+	BytesPerSecond float64 `mapstructure:"bytes_per_second"`
+
+	// Enabled is a flag that enables or disables rate limiting.
+	// Default is false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Key is a configuration of the limits applied to each value of a resource attribute.
+	Key RateLimitKeyConfig `mapstructure:"key"`
+
+	// MessagesPerSecond is the limit of messages written per second in total.
+	// Default is 0, which means that messages are not limited.
+	MessagesPerSecond float64 `mapstructure:"messages_per_second"`
+
+	// MinSeverity is the lowest severity of messages over the limit that are still written by the "downgrade" action.
+	// Possible values are "TRACE", "DEBUG", "INFO", "WARN", "ERROR" and "FATAL".
+	// Default value is "WARN".
+	MinSeverity string `mapstructure:"min_severity"`
+}
+
+type RateLimitKeyConfig struct {
+	// Attribute is the name of the resource attribute whose values are limited separately, such as "service.name".
+	// Default is empty, which means that there are no limits per key.
+	Attribute string `mapstructure:"attribute"`
+
+	// BytesPerSecond is the limit of bytes of GELF messages written per second for each value of Attribute.
+	// Default is 0, which means that bytes are not limited per key.
+	BytesPerSecond float64 `mapstructure:"bytes_per_second"`
+
+	// MessagesPerSecond is the limit of messages written per second for each value of Attribute.
+	// Default is 0, which means that messages are not limited per key.
+	MessagesPerSecond float64 `mapstructure:"messages_per_second"`
+}
+
+type RouteConfig struct {
+	// Endpoint is the address of the GELF input of the route.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Endpoints is a list of GELF inputs of the route the messages are balanced across.
+	// It is mutually exclusive with Endpoint.
+	Endpoints []EndpointConfig `mapstructure:"endpoints"`
+
+	// EndpointTLS overrides the TLS settings of the exporter for the endpoints of the route.
+	// It is only used by the gelftcp exporter.
+	EndpointTLS RouteEndpointTLS `mapstructure:"endpoint_tls"`
+
+	// FailoverEndpoints is an ordered list of GELF inputs of the route used when its endpoints fail.
+	FailoverEndpoints []string `mapstructure:"failover_endpoints"`
+
+	// Match is the condition logs must satisfy to be sent to the route.
+	Match RouteMatchConfig `mapstructure:"match"`
+
+	// Name is the unique name of the route.
+	Name string `mapstructure:"name"`
+}
+
+type RouteEndpointTLS struct {
+	// Enabled is a flag that enables or disables TLS.
+	// Default is the setting of the exporter.
+	Enabled *bool `mapstructure:"enabled"`
+
+	// InsecureSkipVerify is a flag that determines whether to skip verification of the server's certificate chain and host name.
+	// Default is the setting of the exporter.
+	InsecureSkipVerify *bool `mapstructure:"insecure_skip_verify"`
+}
+
+type RouteMatchConfig struct {
+	// Attribute is the name of the attribute compared with Value or Regex.
+	Attribute string `mapstructure:"attribute"`
+
+	// Condition is an OTTL condition evaluated in the resource or log context, depending on Source.
+	// It is mutually exclusive with Attribute.
+	Condition string `mapstructure:"condition"`
+
+	// Regex is a regular expression the attribute value must match.
+	Regex string `mapstructure:"regex"`
+
+	// Source is where the attribute is looked up or the condition is evaluated.
+	// Possible values are "resource" and "log".
+	// Default value is "resource".
+	Source string `mapstructure:"source"`
+
+	// Value is the exact value the attribute must have.
+	Value string `mapstructure:"value"`
+}
+
+type RoutingKeyConfig struct {
+	// Attribute is the name of the attribute whose value is the routing key.
+	// When set, logs are routed across Endpoints by consistent hashing of the routing key,
+	// so that all logs with the same value are sent to the same endpoint.
+	// Logs without the attribute are balanced according to LoadBalancing.
+	// Default is empty, which means that logs are not routed by key.
+	Attribute string `mapstructure:"attribute"`
+
+	// Source is where the attribute is looked up.
+	// Possible values are "resource" and "log".
+	// Default value is "resource".
+	Source string `mapstructure:"source"`
+}
+
+type ResolverConfig struct {
+	// Hosts is a static map of host names to IP addresses, consulted before any DNS lookup.
+	Hosts map[string][]string `mapstructure:"hosts"`
+
+	// Nameservers is a list of nameserver addresses in the form "host" or "host:port".
+	// Default is empty, which means that the system resolver is used.
+	Nameservers []string `mapstructure:"nameservers"`
+
+	// Protocol is the protocol used to query the nameservers.
+	// Possible values are "udp", "tcp" and "dot" (DNS over TLS).
+	// Default value is "udp".
+	Protocol string `mapstructure:"protocol"`
+
+	// Timeout is the timeout of a single DNS lookup.
+	// Default value is 5s.
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// TLSServerName is the name used to verify the nameserver certificate when Protocol is "dot".
+	// Default is the nameserver host.
+	TLSServerName string `mapstructure:"tls_server_name"`
 }
 
 func (cfg *Config) Validate() error {
-	if cfg.Endpoint == "" {
-		return errors.New("GELF input endpoint must be specified")
+	if err := validateEndpoints(cfg.Endpoint, cfg.Endpoints, cfg.FailoverEndpoints); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(cfg.Routes))
+
+	for _, route := range cfg.Routes {
+		if err := route.Validate(); err != nil {
+			return err
+		}
+
+		if names[route.Name] {
+			return fmt.Errorf("duplicate route name %q", route.Name)
+		}
+
+		names[route.Name] = true
+	}
+
+	mirrors := make(map[string]bool, len(cfg.Mirrors))
+
+	for _, mirror := range cfg.Mirrors {
+		if err := mirror.Validate(); err != nil {
+			return err
+		}
+
+		if mirrors[mirror.Name] {
+			return fmt.Errorf("duplicate mirror name %q", mirror.Name)
+		}
+
+		mirrors[mirror.Name] = true
+	}
+
+	if err := cfg.Failover.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.LoadBalancing.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.CircuitBreaker.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.DeadLetter.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.EndpointBackoff.Validate(); err != nil {
+		return fmt.Errorf("invalid endpoint backoff: %w", err)
+	}
+
+	switch cfg.ConnectOnStart {
+	case ConnectOnStartBlocking, ConnectOnStartBackground, ConnectOnStartLazy:
+		break
+	default:
+		return errors.New("invalid connect on start mode")
 	}
 
 	switch cfg.EndpointRefreshStrategy {
-	case EndpointRefreshStrategyNone, EndpointRefreshStrategyInterval, EndpointRefreshStrategyPerMessage:
+	case EndpointRefreshStrategyNone, EndpointRefreshStrategyInterval, EndpointRefreshStrategyDNSTTL, EndpointRefreshStrategyPerMessage:
 		break
 	default:
 		return errors.New("invalid endpoint refresh strategy")
 	}
 
+	if cfg.EndpointRefreshMinTTL < 0 || cfg.EndpointRefreshMaxTTL < cfg.EndpointRefreshMinTTL {
+		return errors.New("endpoint refresh TTL bounds must satisfy 0 <= min <= max")
+	}
+
+	switch cfg.IPFamily {
+	case IPFamilyAny, IPFamilyIPv4, IPFamilyIPv6, IPFamilyPreferIPv4, IPFamilyPreferIPv6:
+		break
+	default:
+		return errors.New("invalid IP family")
+	}
+
+	if err := cfg.Priority.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.RateLimit.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Resolver.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout must not be negative")
+	}
+
+	if cfg.MaxInflightBytes < 0 {
+		return errors.New("max inflight bytes must not be negative")
+	}
+
+	return cfg.RoutingKey.Validate()
+}
+
+func validateEndpoints(endpoint string, endpoints []EndpointConfig, failoverEndpoints []string) error {
+	if endpoint == "" && len(endpoints) == 0 {
+		return errors.New("GELF input endpoint must be specified")
+	}
+
+	if endpoint != "" && len(endpoints) > 0 {
+		return errors.New("endpoint and endpoints are mutually exclusive")
+	}
+
+	if endpoint != "" {
+		endpoints = []EndpointConfig{{Endpoint: endpoint}}
+	}
+
+	for _, endpoint := range endpoints {
+		if err := endpoint.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, endpoint := range failoverEndpoints {
+		failover := EndpointConfig{Endpoint: endpoint}
+
+		if err := failover.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *CircuitBreakerConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.CoolDown <= 0 {
+		return errors.New("circuit breaker cool down must be positive")
+	}
+
+	if cfg.FailureThreshold < 1 || cfg.SuccessThreshold < 1 {
+		return errors.New("circuit breaker thresholds must be positive")
+	}
+
+	return nil
+}
+
+func (cfg *DeadLetterConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.Path == "" {
+		return errors.New("dead letter path must be specified")
+	}
+
+	if cfg.MaxSize <= 0 || cfg.MaxBackups < 0 {
+		return errors.New("invalid dead letter rotation settings")
+	}
+
+	return nil
+}
+
+func (cfg *EndpointConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("GELF input endpoint must be specified")
+	}
+
+	if IsSRVEndpoint(cfg.Endpoint) && strings.ContainsAny(strings.TrimPrefix(cfg.Endpoint, EndpointSRVScheme), ":/") {
+		return errors.New("SRV endpoint must be a plain DNS name")
+	}
+
+	if cfg.Weight < 0 {
+		return errors.New("endpoint weight must not be negative")
+	}
+
+	return nil
+}
+
+func (cfg *FailoverConfig) Validate() error {
+	if cfg.ProbeInterval < 1 || cfg.WriteFailureThreshold < 1 {
+		return errors.New("invalid failover settings")
+	}
+
+	return nil
+}
+
+func (cfg *LoadBalancingConfig) Validate() error {
+	switch cfg.Strategy {
+	case LoadBalancingRoundRobin, LoadBalancingRandom, LoadBalancingLeastInflight:
+		break
+	default:
+		return errors.New("invalid load balancing strategy")
+	}
+
+	if cfg.EjectionThreshold < 1 || cfg.EjectionDuration < 0 {
+		return errors.New("invalid load balancing ejection settings")
+	}
+
+	return nil
+}
+
+func (cfg *MirrorConfig) Validate() error {
+	if cfg.Name == "" {
+		return errors.New("mirror name must be specified")
+	}
+
+	if err := validateEndpoints(cfg.Endpoint, cfg.Endpoints, cfg.FailoverEndpoints); err != nil {
+		return fmt.Errorf("mirror %s: %w", cfg.Name, err)
+	}
+
+	if cfg.QueueSize < 0 {
+		return fmt.Errorf("mirror %s: queue size must not be negative", cfg.Name)
+	}
+
+	if cfg.SamplingRatio != nil && (*cfg.SamplingRatio < 0 || *cfg.SamplingRatio > 1) {
+		return fmt.Errorf("mirror %s: sampling ratio must be between 0 and 1", cfg.Name)
+	}
+
+	return nil
+}
+
+func (cfg *PriorityConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	t := cfg.ShedThresholds
+
+	if t.Debug <= 0 || t.Debug > t.Info || t.Info > t.Warn || t.Warn > 1 {
+		return errors.New("priority shed thresholds must satisfy 0 < debug <= info <= warn <= 1")
+	}
+
+	return nil
+}
+
+func (cfg *RateLimitConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	switch cfg.Action {
+	case RateLimitActionBlock, RateLimitActionDowngrade, RateLimitActionDrop:
+		break
+	default:
+		return errors.New("invalid rate limit action")
+	}
+
+	if _, ok := severityNumbers[strings.ToUpper(cfg.MinSeverity)]; !ok {
+		return errors.New("invalid rate limit min severity")
+	}
+
+	if cfg.BytesPerSecond < 0 || cfg.MessagesPerSecond < 0 || cfg.Key.BytesPerSecond < 0 || cfg.Key.MessagesPerSecond < 0 {
+		return errors.New("rate limits must not be negative")
+	}
+
+	if (cfg.Key.BytesPerSecond > 0 || cfg.Key.MessagesPerSecond > 0) && cfg.Key.Attribute == "" {
+		return errors.New("rate limit key attribute must be specified for limits per key")
+	}
+
+	if cfg.BytesPerSecond == 0 && cfg.MessagesPerSecond == 0 && cfg.Key.BytesPerSecond == 0 && cfg.Key.MessagesPerSecond == 0 {
+		return errors.New("rate limit requires a limit of messages or bytes")
+	}
+
+	return nil
+}
+
+func (cfg *RouteConfig) Validate() error {
+	if cfg.Name == "" {
+		return errors.New("route name must be specified")
+	}
+
+	if cfg.Name == DefaultRouteName {
+		return fmt.Errorf("route name %q is reserved for the endpoints of the exporter", DefaultRouteName)
+	}
+
+	if err := validateEndpoints(cfg.Endpoint, cfg.Endpoints, cfg.FailoverEndpoints); err != nil {
+		return fmt.Errorf("route %s: %w", cfg.Name, err)
+	}
+
+	if err := cfg.Match.Validate(); err != nil {
+		return fmt.Errorf("route %s: %w", cfg.Name, err)
+	}
+
+	return nil
+}
+
+func (cfg *RouteMatchConfig) Validate() error {
+	switch cfg.Source {
+	case "", RoutingKeySourceResource, RoutingKeySourceLog:
+		break
+	default:
+		return errors.New("invalid route match source")
+	}
+
+	if cfg.Condition != "" && (cfg.Attribute != "" || cfg.Value != "" || cfg.Regex != "") {
+		return errors.New("route match condition and attribute are mutually exclusive")
+	}
+
+	if cfg.Condition == "" && cfg.Attribute == "" {
+		return errors.New("route match attribute or condition must be specified")
+	}
+
+	if cfg.Attribute != "" && (cfg.Value == "") == (cfg.Regex == "") {
+		return errors.New("route match attribute requires exactly one of value and regex")
+	}
+
+	_, err := newRouteMatcher(cfg, component.TelemetrySettings{Logger: zap.NewNop()})
+
+	return err
+}
+
+func (cfg *RoutingKeyConfig) Validate() error {
+	switch cfg.Source {
+	case RoutingKeySourceResource, RoutingKeySourceLog:
+		return nil
+	}
+
+	return errors.New("invalid routing key source")
+}
+
+func (cfg *ResolverConfig) Validate() error {
+	switch cfg.Protocol {
+	case ResolverProtocolUDP, ResolverProtocolTCP, ResolverProtocolDoT:
+		break
+	default:
+		return errors.New("invalid resolver protocol")
+	}
+
+	if cfg.Timeout <= 0 {
+		return errors.New("resolver timeout must be positive")
+	}
+
+	for host, addresses := range cfg.Hosts {
+		for _, address := range addresses {
+			if net.ParseIP(address) == nil {
+				return fmt.Errorf("invalid resolver hosts address %q for %s", address, host)
+			}
+		}
+	}
+
 	return nil
 }
 
 // CreateDefaultConfig creates the default configuration for the exporter.
+// Batches are queued, so that an unavailable GELF input does not block the pipeline, and are sent
+// by few consumers, as messages are written one by one over a single connection per endpoint.
+// Retries start early, as GELF inputs usually recover quickly after a restart.
 func CreateDefaultConfig() component.Config {
+	queueConfig := exporterhelper.NewDefaultQueueConfig()
+	queueConfig.NumConsumers = DefaultQueueNumConsumers
+	queueConfig.QueueSize = DefaultQueueSize
+
+	retryConfig := configretry.NewDefaultBackOffConfig()
+	retryConfig.InitialInterval = DefaultRetryInitialInterval
+	retryConfig.MaxElapsedTime = DefaultRetryMaxElapsedTime
+	retryConfig.MaxInterval = DefaultRetryMaxInterval
+
 	return &Config{
+		CircuitBreaker: CircuitBreakerConfig{
+			CoolDown:         DefaultCircuitBreakerCoolDown,
+			FailureThreshold: DefaultCircuitBreakerFailures,
+			SuccessThreshold: DefaultCircuitBreakerSuccesses,
+		},
+		ConnectOnStart: ConnectOnStartBlocking,
+		DeadLetter: DeadLetterConfig{
+			MaxBackups: DefaultDeadLetterMaxBackups,
+			MaxSize:    DefaultDeadLetterMaxSize,
+		},
+		EndpointBackoff: configretry.BackOffConfig{
+			Enabled:             true,
+			InitialInterval:     DefaultEndpointBackoffInitial,
+			MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+			MaxInterval:         DefaultEndpointBackoffMaxInterval,
+			Multiplier:          DefaultEndpointBackoffMultiplier,
+			RandomizationFactor: DefaultEndpointBackoffJitter,
+		},
 		EndpointInitBackoff:     DefaultEndpointInitBackoff,
 		EndpointInitRetries:     DefaultEndpointInitRetries,
 		EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+		EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+		EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 		EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+		Failover: FailoverConfig{
+			ProbeInterval:         DefaultFailoverProbeInterval,
+			WriteFailureThreshold: DefaultFailoverWriteFailures,
+		},
+		IPFamily: IPFamilyAny,
+		LoadBalancing: LoadBalancingConfig{
+			EjectionDuration:  DefaultEjectionDuration,
+			EjectionThreshold: DefaultEjectionThreshold,
+			Strategy:          LoadBalancingRoundRobin,
+		},
+		Priority: PriorityConfig{
+			ShedThresholds: PriorityShedThresholds{
+				Debug: DefaultPriorityShedDebug,
+				Info:  DefaultPriorityShedInfo,
+				Warn:  DefaultPriorityShedWarn,
+			},
+		},
+		QueueConfig: queueConfig,
+		RateLimit: RateLimitConfig{
+			Action:      RateLimitActionDrop,
+			MinSeverity: DefaultRateLimitMinSeverity,
+		},
+		Resolver: ResolverConfig{
+			Protocol: ResolverProtocolUDP,
+			Timeout:  DefaultResolverTimeout,
+		},
+		RetryConfig: retryConfig,
+		RoutingKey: RoutingKeyConfig{
+			Source: RoutingKeySourceResource,
+		},
+		ShutdownTimeout: DefaultShutdownTimeout,
+		TimeoutConfig: exporterhelper.TimeoutConfig{
+			Timeout: DefaultTimeout,
+		},
 	}
 }
+
+// EndpointConfigs returns the configured endpoints, with Endpoint as the only one if Endpoints is empty.
+func (cfg *Config) EndpointConfigs() []EndpointConfig {
+	if len(cfg.Endpoints) > 0 {
+		return cfg.Endpoints
+	}
+
+	return []EndpointConfig{{Endpoint: cfg.Endpoint, Weight: 1}}
+}
+
+// withEndpoints returns the configuration of other endpoints, such as those of a route or a mirror,
+// inheriting all other settings.
+func (cfg *Config) withEndpoints(endpoint string, endpoints []EndpointConfig, failoverEndpoints []string) *Config {
+	endpointsCfg := *cfg
+	endpointsCfg.Endpoint = endpoint
+	endpointsCfg.Endpoints = endpoints
+	endpointsCfg.FailoverEndpoints = failoverEndpoints
+	endpointsCfg.Mirrors = nil
+	endpointsCfg.Routes = nil
+
+	return &endpointsCfg
+}
+
+// endpointBackOff returns the backoff between retries to initialize the endpoint and the maximum time spent retrying.
+// The backoff is fixed and not limited in time if the deprecated EndpointInitBackoff is set, and nil if retries are disabled.
+func (cfg *Config) endpointBackOff() (*backoff.ExponentialBackOff, time.Duration) {
+	var maxElapsed time.Duration
+
+	b := backoff.NewExponentialBackOff()
+
+	switch {
+	case cfg.EndpointInitBackoff > 0:
+		b.InitialInterval = time.Duration(cfg.EndpointInitBackoff) * time.Second
+		b.MaxInterval = b.InitialInterval
+		b.Multiplier = 1
+		b.RandomizationFactor = 0
+	case cfg.EndpointBackoff.Enabled:
+		b.InitialInterval = cfg.EndpointBackoff.InitialInterval
+		b.MaxInterval = cfg.EndpointBackoff.MaxInterval
+		b.Multiplier = cfg.EndpointBackoff.Multiplier
+		b.RandomizationFactor = cfg.EndpointBackoff.RandomizationFactor
+		maxElapsed = cfg.EndpointBackoff.MaxElapsedTime
+	default:
+		return nil, 0
+	}
+
+	b.Reset()
+
+	return b, maxElapsed
+}
+
+// EndpointRefreshTTL clamps the TTL of the resolved endpoint into seconds
+// between EndpointRefreshMinTTL and EndpointRefreshMaxTTL.
+func (cfg *Config) EndpointRefreshTTL(ttl time.Duration) int64 {
+	seconds := int64(ttl / time.Second)
+
+	if seconds < cfg.EndpointRefreshMinTTL {
+		return cfg.EndpointRefreshMinTTL
+	}
+
+	if seconds > cfg.EndpointRefreshMaxTTL {
+		return cfg.EndpointRefreshMaxTTL
+	}
+
+	return seconds
+}
@@ -1,12 +1,16 @@
 package gelfexporter
 
 import (
+	"github.com/cenkalti/backoff/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/xconfmap"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"testing"
+	"time"
 )
 
 func TestConfigLoading(t *testing.T) {
@@ -20,51 +24,1763 @@ func TestConfigLoading(t *testing.T) {
 		{
 			id: component.NewIDWithName(component.MustNewType(UdpExporterType), ""),
 			expected: &Config{
-				Endpoint:                "localhost:12201",
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
 				EndpointInitBackoff:     DefaultEndpointInitBackoff,
 				EndpointInitRetries:     DefaultEndpointInitRetries,
 				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "permessage"),
 			expected: &Config{
-				Endpoint:                "localhost:12201",
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
 				EndpointInitBackoff:     DefaultEndpointInitBackoff,
 				EndpointInitRetries:     DefaultEndpointInitRetries,
 				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 				EndpointRefreshStrategy: EndpointRefreshStrategyPerMessage,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "interval"),
 			expected: &Config{
-				Endpoint:                "localhost:12201",
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
 				EndpointInitBackoff:     DefaultEndpointInitBackoff,
 				EndpointInitRetries:     DefaultEndpointInitRetries,
 				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 				EndpointRefreshStrategy: EndpointRefreshStrategyInterval,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "interval15"),
 			expected: &Config{
-				Endpoint:                "localhost:12201",
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
 				EndpointInitBackoff:     DefaultEndpointInitBackoff,
 				EndpointInitRetries:     DefaultEndpointInitRetries,
 				EndpointRefreshInterval: 15,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 				EndpointRefreshStrategy: EndpointRefreshStrategyInterval,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "custominit"),
 			expected: &Config{
-				Endpoint:                "localhost:12201",
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
 				EndpointInitBackoff:     12,
 				EndpointInitRetries:     3,
 				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
 				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "ipv4"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyIPv4,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "srv"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "srv://_gelf._udp.logs.example.com",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyInterval,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "dnsttl"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   120,
+				EndpointRefreshMinTTL:   10,
+				EndpointRefreshStrategy: EndpointRefreshStrategyDNSTTL,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "resolver"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "graylog.internal:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Hosts:         map[string][]string{"graylog.internal": {"10.0.0.1", "10.0.0.2"}},
+					Nameservers:   []string{"10.0.0.53", "10.0.1.53:853"},
+					Protocol:      ResolverProtocolDoT,
+					Timeout:       2 * time.Second,
+					TLSServerName: "dns.internal",
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "endpoints"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoints: []EndpointConfig{
+					{Endpoint: "graylog1:12201", Weight: 2},
+					{Endpoint: "graylog2:12201"},
+				},
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  60,
+					EjectionThreshold: 5,
+					Strategy:          LoadBalancingLeastInflight,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "failover"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         10,
+					WriteFailureThreshold: 5,
+				},
+				FailoverEndpoints: []string{"dr1:12201", "dr2:12201"},
+				IPFamily:          IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "routingkey"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoints: []EndpointConfig{{Endpoint: "graylog1:12201"}, {Endpoint: "graylog2:12201"}},
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Attribute: "k8s.pod.uid",
+					Source:    RoutingKeySourceLog,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "routes"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "graylog:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				Routes: []RouteConfig{
+					{
+						Endpoint: "graylog:12211",
+						Match:    RouteMatchConfig{Attribute: "tenant", Value: "acme"},
+						Name:     "acme",
+					},
+					{
+						Endpoints: []EndpointConfig{{Endpoint: "graylog1:12212"}, {Endpoint: "graylog2:12212"}},
+						Match:     RouteMatchConfig{Condition: `attributes["tenant"] == "globex"`, Source: RoutingKeySourceLog},
+						Name:      "globex",
+					},
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "mirrors"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "graylog-old:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Mirrors: []MirrorConfig{
+					{
+						Endpoint:      "graylog-new:12201",
+						Name:          "new",
+						QueueSize:     500,
+						SamplingRatio: func() *float64 { v := 0.25; return &v }(),
+					},
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "queue"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: 4,
+					QueueSize:    10000,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     2 * time.Second,
+					MaxElapsedTime:      10 * time.Minute,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: 5 * time.Second,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: 30 * time.Second,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "persistent_queue"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+					StorageID:    func() *component.ID { id := component.MustNewID("file_storage"); return &id }(),
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "background"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBackground,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "circuitbreaker"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         time.Minute,
+					Enabled:          true,
+					FailureThreshold: 3,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "ratelimit"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:            RateLimitActionDowngrade,
+					Enabled:           true,
+					Key:               RateLimitKeyConfig{Attribute: "service.name", MessagesPerSecond: 100},
+					MessagesPerSecond: 1000,
+					MinSeverity:       "ERROR",
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "deadletter"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					Enabled:    true,
+					MaxBackups: 2,
+					MaxSize:    1048576,
+					Path:       "/var/lib/otelcol/gelf-dead-letter.log",
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "maxinflight"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				MaxInflightBytes: 16777216,
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "priority"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultEndpointBackoffInitial,
+					MaxElapsedTime:      DefaultEndpointBackoffMaxElapsed,
+					MaxInterval:         DefaultEndpointBackoffMaxInterval,
+					Multiplier:          DefaultEndpointBackoffMultiplier,
+					RandomizationFactor: DefaultEndpointBackoffJitter,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					Enabled: true,
+					ShedThresholds: PriorityShedThresholds{
+						Debug: 0.3,
+						Info:  0.6,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(UdpExporterType), "backoff"),
+			expected: &Config{
+				CircuitBreaker: CircuitBreakerConfig{
+					CoolDown:         DefaultCircuitBreakerCoolDown,
+					FailureThreshold: DefaultCircuitBreakerFailures,
+					SuccessThreshold: DefaultCircuitBreakerSuccesses,
+				},
+				ConnectOnStart: ConnectOnStartBlocking,
+				DeadLetter: DeadLetterConfig{
+					MaxBackups: DefaultDeadLetterMaxBackups,
+					MaxSize:    DefaultDeadLetterMaxSize,
+				},
+				Endpoint: "localhost:12201",
+				EndpointBackoff: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     500 * time.Millisecond,
+					MaxElapsedTime:      2 * time.Minute,
+					MaxInterval:         time.Minute,
+					Multiplier:          2,
+					RandomizationFactor: 0.2,
+				},
+				EndpointInitBackoff:     DefaultEndpointInitBackoff,
+				EndpointInitRetries:     DefaultEndpointInitRetries,
+				EndpointRefreshInterval: DefaultEndpointRefreshInterval,
+				EndpointRefreshMaxTTL:   DefaultEndpointRefreshMaxTTL,
+				EndpointRefreshMinTTL:   DefaultEndpointRefreshMinTTL,
+				EndpointRefreshStrategy: EndpointRefreshStrategyNone,
+				Failover: FailoverConfig{
+					ProbeInterval:         DefaultFailoverProbeInterval,
+					WriteFailureThreshold: DefaultFailoverWriteFailures,
+				},
+				IPFamily: IPFamilyAny,
+				LoadBalancing: LoadBalancingConfig{
+					EjectionDuration:  DefaultEjectionDuration,
+					EjectionThreshold: DefaultEjectionThreshold,
+					Strategy:          LoadBalancingRoundRobin,
+				},
+				Priority: PriorityConfig{
+					ShedThresholds: PriorityShedThresholds{
+						Debug: DefaultPriorityShedDebug,
+						Info:  DefaultPriorityShedInfo,
+						Warn:  DefaultPriorityShedWarn,
+					},
+				},
+				QueueConfig: exporterhelper.QueueConfig{
+					Enabled:      true,
+					NumConsumers: DefaultQueueNumConsumers,
+					QueueSize:    DefaultQueueSize,
+				},
+				RateLimit: RateLimitConfig{
+					Action:      RateLimitActionDrop,
+					MinSeverity: DefaultRateLimitMinSeverity,
+				},
+				Resolver: ResolverConfig{
+					Protocol: ResolverProtocolUDP,
+					Timeout:  DefaultResolverTimeout,
+				},
+				RetryConfig: configretry.BackOffConfig{
+					Enabled:             true,
+					InitialInterval:     DefaultRetryInitialInterval,
+					MaxElapsedTime:      DefaultRetryMaxElapsedTime,
+					MaxInterval:         DefaultRetryMaxInterval,
+					Multiplier:          backoff.DefaultMultiplier,
+					RandomizationFactor: backoff.DefaultRandomizationFactor,
+				},
+				RoutingKey: RoutingKeyConfig{
+					Source: RoutingKeySourceResource,
+				},
+				ShutdownTimeout: DefaultShutdownTimeout,
+				TimeoutConfig: exporterhelper.TimeoutConfig{
+					Timeout: DefaultTimeout,
+				},
 			},
 		},
 	}
@@ -97,6 +1813,164 @@ func TestConfigValidation(t *testing.T) {
 			}(),
 			wantErr: "GELF input endpoint must be specified",
 		},
+		{
+			name: "EndpointAndEndpoints",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Endpoints = []EndpointConfig{{Endpoint: "localhost:12202"}}
+				return cfg
+			}(),
+			wantErr: "endpoint and endpoints are mutually exclusive",
+		},
+		{
+			name: "NegativeEndpointWeight",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoints = []EndpointConfig{{Endpoint: "localhost:12201", Weight: -1}}
+				return cfg
+			}(),
+			wantErr: "endpoint weight must not be negative",
+		},
+		{
+			name: "InvalidFailoverEndpoint",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.FailoverEndpoints = []string{""}
+				return cfg
+			}(),
+			wantErr: "GELF input endpoint must be specified",
+		},
+		{
+			name: "InvalidFailoverSettings",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Failover.ProbeInterval = 0
+				return cfg
+			}(),
+			wantErr: "invalid failover settings",
+		},
+		{
+			name: "InvalidLoadBalancingStrategy",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.LoadBalancing.Strategy = "invalid"
+				return cfg
+			}(),
+			wantErr: "invalid load balancing strategy",
+		},
+		{
+			name: "InvalidRoutingKeySource",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.RoutingKey.Source = "scope"
+				return cfg
+			}(),
+			wantErr: "invalid routing key source",
+		},
+		{
+			name: "RouteWithoutName",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Endpoint: "localhost:12202", Match: RouteMatchConfig{Attribute: "tenant", Value: "acme"}}}
+				return cfg
+			}(),
+			wantErr: "route name must be specified",
+		},
+		{
+			name: "DuplicateRouteName",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Endpoint: "localhost:12202", Match: RouteMatchConfig{Attribute: "tenant", Value: "acme"}}, {Name: "acme", Endpoint: "localhost:12203", Match: RouteMatchConfig{Attribute: "tenant", Value: "acme"}}}
+				return cfg
+			}(),
+			wantErr: "duplicate route name \"acme\"",
+		},
+		{
+			name: "RouteWithoutEndpoint",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Match: RouteMatchConfig{Attribute: "tenant", Value: "acme"}}}
+				return cfg
+			}(),
+			wantErr: "route acme: GELF input endpoint must be specified",
+		},
+		{
+			name: "RouteWithValueAndRegex",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Endpoint: "localhost:12202", Match: RouteMatchConfig{Attribute: "tenant", Value: "acme", Regex: "^acme"}}}
+				return cfg
+			}(),
+			wantErr: "route acme: route match attribute requires exactly one of value and regex",
+		},
+		{
+			name: "RouteWithInvalidRegex",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Endpoint: "localhost:12202", Match: RouteMatchConfig{Attribute: "tenant", Regex: "("}}}
+				return cfg
+			}(),
+			wantErr: "route acme: invalid route match regex: error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "RouteWithConditionAndAttribute",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Endpoint: "localhost:12202", Match: RouteMatchConfig{Attribute: "tenant", Condition: "true"}}}
+				return cfg
+			}(),
+			wantErr: "route acme: route match condition and attribute are mutually exclusive",
+		},
+		{
+			name: "MirrorWithoutName",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Mirrors = []MirrorConfig{{Endpoint: "localhost:12202"}}
+				return cfg
+			}(),
+			wantErr: "mirror name must be specified",
+		},
+		{
+			name: "DuplicateMirrorName",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Mirrors = []MirrorConfig{{Name: "new", Endpoint: "localhost:12202"}, {Name: "new", Endpoint: "localhost:12203"}}
+				return cfg
+			}(),
+			wantErr: "duplicate mirror name \"new\"",
+		},
+		{
+			name: "InvalidMirrorSamplingRatio",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Mirrors = []MirrorConfig{{Name: "new", Endpoint: "localhost:12202", SamplingRatio: func() *float64 { v := 1.5; return &v }()}}
+				return cfg
+			}(),
+			wantErr: "mirror new: sampling ratio must be between 0 and 1",
+		},
+		{
+			name: "InvalidSRVEndpoint",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "srv://_gelf._udp.logs.example.com:12201"
+				return cfg
+			}(),
+			wantErr: "SRV endpoint must be a plain DNS name",
+		},
 		{
 			name: "InvalidEndpointRefreshStrategy",
 			cfg: func() *Config {
@@ -107,6 +1981,162 @@ func TestConfigValidation(t *testing.T) {
 			}(),
 			wantErr: "invalid endpoint refresh strategy",
 		},
+		{
+			name: "InvalidEndpointRefreshTTL",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.EndpointRefreshMinTTL = 60
+				cfg.EndpointRefreshMaxTTL = 30
+				return cfg
+			}(),
+			wantErr: "endpoint refresh TTL bounds must satisfy 0 <= min <= max",
+		},
+		{
+			name: "InvalidResolverProtocol",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Resolver.Protocol = "doh"
+				return cfg
+			}(),
+			wantErr: "invalid resolver protocol",
+		},
+		{
+			name: "InvalidResolverHosts",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Resolver.Hosts = map[string][]string{"graylog.internal": {"graylog"}}
+				return cfg
+			}(),
+			wantErr: "invalid resolver hosts address \"graylog\" for graylog.internal",
+		},
+		{
+			name: "InvalidIPFamily",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.IPFamily = "ipv5"
+				return cfg
+			}(),
+			wantErr: "invalid IP family",
+		},
+		{
+			name: "RouteWithInvalidCondition",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Routes = []RouteConfig{{Name: "acme", Endpoint: "localhost:12202", Match: RouteMatchConfig{Condition: "tenant =="}}}
+				return cfg
+			}(),
+			wantErr: "route acme: invalid route match condition: condition has invalid syntax: 1:10: unexpected token \"<EOF>\" (expected Field (\".\" Field)*)",
+		},
+		{
+			name: "DeadLetterWithoutPath",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.DeadLetter.Enabled = true
+				cfg.Endpoint = "localhost:12201"
+				return cfg
+			}(),
+			wantErr: "dead letter path must be specified",
+		},
+		{
+			name: "NegativeMaxInflightBytes",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.MaxInflightBytes = -1
+				return cfg
+			}(),
+			wantErr: "max inflight bytes must not be negative",
+		},
+		{
+			name: "InvalidPriorityShedThresholds",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Priority.Enabled = true
+				cfg.Priority.ShedThresholds.Debug = 0.8
+				return cfg
+			}(),
+			wantErr: "priority shed thresholds must satisfy 0 < debug <= info <= warn <= 1",
+		},
+		{
+			name: "InvalidRateLimitAction",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.RateLimit.Action = "queue"
+				cfg.RateLimit.Enabled = true
+				cfg.RateLimit.MessagesPerSecond = 100
+				return cfg
+			}(),
+			wantErr: "invalid rate limit action",
+		},
+		{
+			name: "RateLimitWithoutLimits",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.RateLimit.Enabled = true
+				return cfg
+			}(),
+			wantErr: "rate limit requires a limit of messages or bytes",
+		},
+		{
+			name: "RateLimitPerKeyWithoutAttribute",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.RateLimit.Enabled = true
+				cfg.RateLimit.Key.BytesPerSecond = 1024
+				return cfg
+			}(),
+			wantErr: "rate limit key attribute must be specified for limits per key",
+		},
+		{
+			name: "InvalidCircuitBreakerThreshold",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.CircuitBreaker.Enabled = true
+				cfg.CircuitBreaker.FailureThreshold = 0
+				cfg.Endpoint = "localhost:12201"
+				return cfg
+			}(),
+			wantErr: "circuit breaker thresholds must be positive",
+		},
+		{
+			name: "InvalidEndpointBackoff",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.EndpointBackoff.RandomizationFactor = 2
+				return cfg
+			}(),
+			wantErr: "invalid endpoint backoff: 'randomization_factor' must be within [0, 1]",
+		},
+		{
+			name: "InvalidConnectOnStart",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.ConnectOnStart = "eager"
+				cfg.Endpoint = "localhost:12201"
+				return cfg
+			}(),
+			wantErr: "invalid connect on start mode",
+		},
+		{
+			name: "NegativeShutdownTimeout",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.ShutdownTimeout = -time.Second
+				return cfg
+			}(),
+			wantErr: "shutdown timeout must not be negative",
+		},
 		{
 			name: "Success",
 			cfg: func() *Config {
@@ -129,3 +2159,38 @@ func TestConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigEndpointRefreshTTL(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.EndpointRefreshMinTTL = 10
+	cfg.EndpointRefreshMaxTTL = 120
+
+	assert.Equal(t, int64(10), cfg.EndpointRefreshTTL(0))
+	assert.Equal(t, int64(10), cfg.EndpointRefreshTTL(3*time.Second))
+	assert.Equal(t, int64(45), cfg.EndpointRefreshTTL(45*time.Second))
+	assert.Equal(t, int64(120), cfg.EndpointRefreshTTL(time.Hour))
+}
+
+func TestEndpointBackOff(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+
+	b, maxElapsed := cfg.endpointBackOff()
+	require.NotNil(t, b)
+	assert.Equal(t, time.Minute, maxElapsed)
+	assert.InDelta(t, float64(time.Second), float64(b.NextBackOff()), float64(time.Second/2))
+	assert.InDelta(t, float64(1500*time.Millisecond), float64(b.NextBackOff()), float64(750*time.Millisecond))
+
+	cfg.EndpointInitBackoff = 10
+
+	b, maxElapsed = cfg.endpointBackOff()
+	require.NotNil(t, b)
+	assert.Zero(t, maxElapsed)
+	assert.Equal(t, 10*time.Second, b.NextBackOff())
+	assert.Equal(t, 10*time.Second, b.NextBackOff())
+
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointBackoff.Enabled = false
+
+	b, _ = cfg.endpointBackOff()
+	assert.Nil(t, b)
+}
@@ -0,0 +1,380 @@
+package gelfexporter
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dialer creates a GELF writer for a resolved address of the endpoint.
+type Dialer func(address string) (gelf.Writer, error)
+
+// errConnectionClosed is returned when a GELF writer would be initialized after the connection was closed.
+var errConnectionClosed = errors.New("connection is closed")
+
+// Connection is a GELF writer for a single endpoint, refreshed according to the endpoint refresh strategy.
+// It is safe for concurrent use: writes use the current GELF writer without locking, while initializing
+// a new one is serialized and swaps it in once it is connected. A replaced GELF writer is closed
+// after the writes in flight on it finished. Once closed, no new GELF writer is initialized,
+// and an initialization in progress stops retrying.
+type Connection struct {
+	closeOnce                 sync.Once
+	closed                    chan struct{}
+	config                    *Config
+	dialer                    Dialer
+	endpoint                  string
+	logger                    *zap.Logger
+	resolver                  *Resolver
+	writer                    atomic.Pointer[connectionWriter]
+	writerEndpointRefreshTime atomic.Int64
+	writerEndpointTTL         atomic.Int64
+	writerLock                sync.Mutex
+}
+
+// aliveWriter is a GELF writer that reports whether it still reaches its endpoint, such as a writer
+// through a TLS gateway, which can't reconnect once its TLS connection failed.
+type aliveWriter interface {
+	Alive() bool
+}
+
+// connectionWriter is a GELF writer counting the references to it: one held by the connection while
+// the writer is current, and one per write in flight. It is closed once the last reference is released.
+type connectionWriter struct {
+	endpoint string
+	refs     atomic.Int64
+	writer   gelf.Writer
+}
+
+func newConnectionWriter(writer gelf.Writer, endpoint string) *connectionWriter {
+	w := &connectionWriter{endpoint: endpoint, writer: writer}
+	w.refs.Store(1)
+
+	return w
+}
+
+// alive reports whether the writer still reaches its endpoint, which is assumed unless it reports otherwise.
+func (w *connectionWriter) alive() bool {
+	writer, ok := w.writer.(aliveWriter)
+
+	return !ok || writer.Alive()
+}
+
+// acquire adds a reference to the writer, failing if it is already closed.
+func (w *connectionWriter) acquire() bool {
+	for {
+		refs := w.refs.Load()
+
+		if refs == 0 {
+			return false
+		}
+
+		if w.refs.CompareAndSwap(refs, refs+1) {
+			return true
+		}
+	}
+}
+
+// release removes a reference to the writer, closing it if it was the last one.
+func (w *connectionWriter) release() error {
+	if w.refs.Add(-1) > 0 {
+		return nil
+	}
+
+	return w.writer.Close()
+}
+
+func NewConnection(endpoint string, cfg *Config, resolver *Resolver, dialer Dialer, logger *zap.Logger) *Connection {
+	return &Connection{
+		closed:   make(chan struct{}),
+		config:   cfg,
+		dialer:   dialer,
+		endpoint: endpoint,
+		logger:   logger.With(zap.String("endpoint", endpoint)),
+		resolver: resolver,
+	}
+}
+
+// Endpoint returns the configured endpoint of the connection.
+func (c *Connection) Endpoint() string {
+	return c.endpoint
+}
+
+// Init initializes the GELF writer, retrying EndpointInitRetries times with the EndpointBackoff delays.
+func (c *Connection) Init() bool {
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	return c.init()
+}
+
+func (c *Connection) init() bool {
+	var attempts int
+	var initialized bool
+
+	initBackoff, maxElapsed := c.config.endpointBackOff()
+	start := time.Now()
+
+	for attempts < c.config.EndpointInitRetries {
+		attempts++
+
+		err := c.initGelfWriter()
+
+		if initialized = err == nil; initialized || errors.Is(err, errConnectionClosed) ||
+			attempts == c.config.EndpointInitRetries || initBackoff == nil {
+			break
+		}
+
+		delay := initBackoff.NextBackOff()
+
+		if maxElapsed > 0 && time.Since(start)+delay > maxElapsed {
+			break
+		}
+
+		c.logger.Debug(fmt.Sprintf("retrying to initialize GELF writer in %s", delay.String()))
+
+		if !c.wait(delay) {
+			break
+		}
+	}
+
+	if !initialized && !c.isClosed() {
+		c.logger.Error(fmt.Sprintf("failed to initialize GELF writer after %d retries", attempts))
+	}
+
+	return initialized
+}
+
+// wait waits for the delay before the next initialization attempt, returning false if the connection
+// is closed meanwhile, so that closing it doesn't wait for the remaining retries.
+func (c *Connection) wait(delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-c.closed:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (c *Connection) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reconnect closes the current GELF writer once the writes in flight on it finished,
+// and makes a single attempt to initialize a fresh one.
+func (c *Connection) Reconnect() bool {
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	if writer := c.writer.Swap(nil); writer != nil {
+		c.releaseWriter(writer)
+	}
+
+	return c.initGelfWriter() == nil
+}
+
+// Refresh re-initializes the GELF writer when the endpoint refresh strategy requires it.
+// Writes keep using the current GELF writer until the new one is connected.
+func (c *Connection) Refresh() bool {
+	if !c.endpointRefreshRequired() {
+		return true
+	}
+
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	// Another consumer may have refreshed the endpoint while this one waited for the lock.
+	if !c.endpointRefreshRequired() {
+		return true
+	}
+
+	c.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", c.config.EndpointRefreshStrategy))
+
+	return c.init()
+}
+
+// WriteMessage writes the message, initializing the GELF writer first if it isn't yet
+// or if it doesn't reach the endpoint anymore.
+func (c *Connection) WriteMessage(m *gelf.Message) error {
+	if c.config.EndpointRefreshStrategy == EndpointRefreshStrategyPerMessage {
+		c.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", c.config.EndpointRefreshStrategy))
+
+		if !c.Init() {
+			return errors.New("failed to refresh writer endpoint")
+		}
+	}
+
+	writer := c.acquireWriter()
+
+	if writer != nil && !writer.alive() {
+		c.releaseWriter(writer)
+		writer = nil
+	}
+
+	if writer == nil {
+		if err := c.connectWriter(); err != nil {
+			return fmt.Errorf("failed to initialize GELF writer for endpoint %s: %w", c.endpoint, err)
+		}
+
+		if writer = c.acquireWriter(); writer == nil {
+			return fmt.Errorf("GELF writer for endpoint %s was closed", c.endpoint)
+		}
+	}
+
+	defer c.releaseWriter(writer)
+
+	return writer.writer.WriteMessage(m)
+}
+
+// Close closes the GELF writer, or lets the last write in flight on it close it,
+// and prevents initializing a new one afterwards. It interrupts an initialization in progress.
+func (c *Connection) Close() error {
+	// Closing the channel before taking writerLock stops the initialization holding it from retrying.
+	c.closeOnce.Do(func() { close(c.closed) })
+
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	if writer := c.writer.Swap(nil); writer != nil {
+		return writer.release()
+	}
+
+	return nil
+}
+
+// acquireWriter returns the current GELF writer with a reference held for a write, or nil if there is none.
+func (c *Connection) acquireWriter() *connectionWriter {
+	for {
+		writer := c.writer.Load()
+
+		// A writer that can't be acquired was closed after being replaced, so the next load returns its replacement.
+		if writer == nil || writer.acquire() {
+			return writer
+		}
+	}
+}
+
+func (c *Connection) releaseWriter(writer *connectionWriter) {
+	if err := writer.release(); err != nil {
+		c.logger.Warn("failed to close GELF writer", zap.Error(err))
+	}
+}
+
+// connectWriter initializes the GELF writer unless another write initialized it meanwhile.
+func (c *Connection) connectWriter() error {
+	c.writerLock.Lock()
+	defer c.writerLock.Unlock()
+
+	if current := c.writer.Load(); current != nil && current.alive() {
+		return nil
+	}
+
+	return c.initGelfWriter()
+}
+
+// initGelfWriter connects to the first resolved address that accepts the connection.
+// It returns the errors of dialing all addresses if none does, or errConnectionClosed once the connection is closed.
+// It must be called with writerLock held.
+func (c *Connection) initGelfWriter() error {
+	var errs []error
+
+	if c.isClosed() {
+		return fmt.Errorf("endpoint %s: %w", c.endpoint, errConnectionClosed)
+	}
+
+	c.logger.Info(fmt.Sprintf("initializing GELF writer for endpoint %s", c.endpoint))
+
+	endpoints, err := c.resolveWriterEndpoints()
+
+	if err != nil {
+		c.logger.Error(fmt.Sprintf("failed to resolve IP address for %s", c.endpoint), zap.Error(err))
+		return err
+	}
+
+	if current := c.writer.Load(); current != nil && current.alive() && slices.Contains(endpoints, current.endpoint) {
+		c.logger.Debug(fmt.Sprintf("endpoint %s still resolves to %s, keeping current GELF writer", c.endpoint, current.endpoint))
+		return nil
+	}
+
+	for _, endpoint := range endpoints {
+		writer, err := c.dialer(endpoint)
+
+		if err != nil {
+			c.logger.Warn(fmt.Sprintf("failed to initialize GELF writer for address %s", endpoint), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+
+		// The connection may have been closed while dialing, in which case the writer is discarded.
+		if c.isClosed() {
+			if err := writer.Close(); err != nil {
+				c.logger.Warn("failed to close GELF writer", zap.Error(err))
+			}
+
+			return fmt.Errorf("endpoint %s: %w", c.endpoint, errConnectionClosed)
+		}
+
+		c.swapGelfWriter(newConnectionWriter(writer, endpoint))
+		c.logger.Debug(fmt.Sprintf("connected to endpoint %s using %s", c.endpoint, endpoint))
+
+		return nil
+	}
+
+	c.logger.Error(fmt.Sprintf("failed to initialize GELF writer for endpoint %s", c.endpoint))
+
+	if len(errs) == 0 {
+		return fmt.Errorf("no address resolved for endpoint %s", c.endpoint)
+	}
+
+	return errors.Join(errs...)
+}
+
+// swapGelfWriter makes the writer current, closing the previous one once the writes in flight on it finished.
+func (c *Connection) swapGelfWriter(writer *connectionWriter) {
+	previous := c.writer.Swap(writer)
+
+	if previous == nil {
+		return
+	}
+
+	c.logger.Debug(fmt.Sprintf("closing GELF writer for address %s", previous.endpoint))
+	c.releaseWriter(previous)
+}
+
+func (c *Connection) endpointRefreshRequired() bool {
+	switch c.config.EndpointRefreshStrategy {
+	case EndpointRefreshStrategyInterval:
+		return time.Now().Unix()-c.writerEndpointRefreshTime.Load() > c.config.EndpointRefreshInterval
+	case EndpointRefreshStrategyDNSTTL:
+		return time.Now().Unix()-c.writerEndpointRefreshTime.Load() >= c.writerEndpointTTL.Load()
+	}
+
+	return false
+}
+
+func (c *Connection) resolveWriterEndpoints() ([]string, error) {
+	endpoints, ttl, err := c.resolver.Resolve(c.endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.writerEndpointTTL.Store(c.config.EndpointRefreshTTL(ttl))
+	c.writerEndpointRefreshTime.Store(time.Now().Unix())
+
+	c.logger.Debug(fmt.Sprintf("resolved Endpoint %s into %v", c.endpoint, endpoints))
+
+	return endpoints, nil
+}
@@ -0,0 +1,252 @@
+package gelfexporter
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closeTrackingWriter fails writes made after it was closed, optionally blocking writes until released.
+type closeTrackingWriter struct {
+	gelf.Writer
+	block   chan struct{}
+	closed  atomic.Bool
+	writing chan struct{}
+}
+
+func (w *closeTrackingWriter) WriteMessage(_ *gelf.Message) error {
+	if w.writing != nil {
+		w.writing <- struct{}{}
+		<-w.block
+	}
+
+	if w.closed.Load() {
+		return errors.New("write on closed writer")
+	}
+
+	return nil
+}
+
+func (w *closeTrackingWriter) Close() error {
+	w.closed.Store(true)
+	return nil
+}
+
+// aliveTrackingWriter reports whether it still reaches the endpoint, like a writer through a TLS gateway.
+type aliveTrackingWriter struct {
+	closeTrackingWriter
+	dead atomic.Bool
+}
+
+func (w *aliveTrackingWriter) Alive() bool {
+	return !w.dead.Load()
+}
+
+func newTestConnection(dialer Dialer) *Connection {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.EndpointInitRetries = 1
+
+	return NewConnection("127.0.0.1:12201", cfg, NewResolver(cfg), dialer, zap.NewNop())
+}
+
+func TestConnectionClosesWriterAfterWritesInFlight(t *testing.T) {
+	w := &closeTrackingWriter{block: make(chan struct{}), writing: make(chan struct{})}
+	c := newTestConnection(func(string) (gelf.Writer, error) { return w, nil })
+
+	require.True(t, c.Init())
+
+	written := make(chan error)
+
+	go func() {
+		written <- c.WriteMessage(&gelf.Message{})
+	}()
+
+	<-w.writing
+	require.NoError(t, c.Close())
+	assert.False(t, w.closed.Load())
+
+	close(w.block)
+	assert.NoError(t, <-written)
+	assert.True(t, w.closed.Load())
+}
+
+func TestConnectionConcurrentWritesAndReconnects(t *testing.T) {
+	var wg sync.WaitGroup
+	var failures atomic.Int64
+
+	c := newTestConnection(func(string) (gelf.Writer, error) { return &closeTrackingWriter{}, nil })
+
+	require.True(t, c.Init())
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				if err := c.WriteMessage(&gelf.Message{}); err != nil {
+					failures.Add(1)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		require.True(t, c.Reconnect())
+	}
+
+	wg.Wait()
+
+	assert.Zero(t, failures.Load())
+	assert.NoError(t, c.Close())
+}
+
+func TestConnectionDoesNotReconnectAfterClose(t *testing.T) {
+	var dials atomic.Int64
+
+	c := newTestConnection(func(string) (gelf.Writer, error) {
+		dials.Add(1)
+		return &closeTrackingWriter{}, nil
+	})
+
+	require.True(t, c.Init())
+	require.NoError(t, c.Close())
+
+	assert.ErrorIs(t, c.WriteMessage(&gelf.Message{}), errConnectionClosed)
+	assert.False(t, c.Reconnect())
+	assert.False(t, c.Init())
+	assert.Equal(t, int64(1), dials.Load())
+}
+
+func TestConnectionCloseInterruptsRetryingInit(t *testing.T) {
+	dialing := make(chan struct{}, 1)
+
+	c := newTestConnection(func(string) (gelf.Writer, error) {
+		dialing <- struct{}{}
+		return nil, errors.New("connection refused")
+	})
+
+	c.config.EndpointInitRetries = 5
+	c.config.EndpointInitBackoff = 60
+
+	initialized := make(chan bool)
+
+	go func() {
+		initialized <- c.Init()
+	}()
+
+	<-dialing
+
+	// The init waits a minute before retrying, so both return only if closing interrupts the wait.
+	closed := make(chan error)
+
+	go func() {
+		closed <- c.Close()
+	}()
+
+	select {
+	case ok := <-initialized:
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "init kept retrying after close")
+	}
+
+	assert.NoError(t, <-closed)
+	assert.Empty(t, dialing)
+}
+
+func TestConnectionCloseDiscardsWriterDialedMeanwhile(t *testing.T) {
+	dialing := make(chan struct{})
+	dialed := make(chan struct{})
+	w := &closeTrackingWriter{}
+
+	c := newTestConnection(func(string) (gelf.Writer, error) {
+		close(dialing)
+		<-dialed
+		return w, nil
+	})
+
+	initialized := make(chan bool)
+
+	go func() {
+		initialized <- c.Init()
+	}()
+
+	<-dialing
+
+	closed := make(chan error)
+
+	go func() {
+		closed <- c.Close()
+	}()
+
+	// Close waits for the dial in progress, but marks the connection closed before.
+	require.Eventually(t, c.isClosed, time.Second, time.Millisecond)
+	close(dialed)
+
+	assert.False(t, <-initialized)
+	assert.NoError(t, <-closed)
+	assert.True(t, w.closed.Load())
+	assert.Nil(t, c.writer.Load())
+}
+
+func TestConnectionRefreshKeepsWriterOfSameAddress(t *testing.T) {
+	var dials atomic.Int64
+
+	w := &closeTrackingWriter{}
+	c := newTestConnection(func(string) (gelf.Writer, error) {
+		dials.Add(1)
+		return w, nil
+	})
+
+	c.config.EndpointRefreshStrategy = EndpointRefreshStrategyInterval
+
+	require.True(t, c.Init())
+
+	// The endpoint resolves to the same address, so the refresh keeps the connection.
+	c.writerEndpointRefreshTime.Store(0)
+
+	require.True(t, c.Refresh())
+	require.NoError(t, c.WriteMessage(&gelf.Message{}))
+
+	assert.Equal(t, int64(1), dials.Load())
+	assert.False(t, w.closed.Load())
+	assert.NoError(t, c.Close())
+}
+
+func TestConnectionReplacesDeadWriterOfSameAddress(t *testing.T) {
+	var writers []*aliveTrackingWriter
+
+	c := newTestConnection(func(string) (gelf.Writer, error) {
+		writers = append(writers, &aliveTrackingWriter{})
+		return writers[len(writers)-1], nil
+	})
+
+	c.config.EndpointRefreshStrategy = EndpointRefreshStrategyInterval
+
+	require.True(t, c.Init())
+
+	// The endpoint resolves to the same address, but the refresh replaces the writer that can't reach it anymore.
+	writers[0].dead.Store(true)
+	c.writerEndpointRefreshTime.Store(0)
+
+	require.True(t, c.Refresh())
+	require.Len(t, writers, 2)
+	assert.True(t, writers[0].closed.Load())
+
+	// A write replaces it as well, without waiting for the next refresh.
+	writers[1].dead.Store(true)
+
+	require.NoError(t, c.WriteMessage(&gelf.Message{}))
+	require.Len(t, writers, 3)
+	assert.True(t, writers[1].closed.Load())
+	assert.False(t, writers[2].closed.Load())
+	assert.NoError(t, c.Close())
+}
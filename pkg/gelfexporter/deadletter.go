@@ -0,0 +1,206 @@
+package gelfexporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DeadLetterRecord is a line of the dead letter file: a GELF message that could not be delivered and why.
+type DeadLetterRecord struct {
+	Message json.RawMessage `json:"message"`
+	Reason  string          `json:"reason"`
+	Time    time.Time       `json:"time"`
+}
+
+// DeadLetter writes the GELF messages that could not be delivered to a local file, one DeadLetterRecord
+// per line, rotating the file once it exceeds MaxSize and keeping MaxBackups rotated files.
+// All methods are no-ops on a nil DeadLetter.
+type DeadLetter struct {
+	config *DeadLetterConfig
+	file   *os.File
+	lock   sync.Mutex
+	logger *zap.Logger
+	render func(plog.Logs) []*gelf.Message
+	size   int64
+}
+
+// NewDeadLetter returns the dead letter of the exporter rendering logs with render, or nil if it is disabled.
+// The file is opened once the first message is written.
+func NewDeadLetter(cfg *DeadLetterConfig, render func(plog.Logs) []*gelf.Message, logger *zap.Logger) *DeadLetter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	return &DeadLetter{config: cfg, logger: logger, render: render}
+}
+
+// Write writes the message with the reason it could not be delivered.
+func (d *DeadLetter) Write(m *gelf.Message, reason error) {
+	if d == nil {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	if err := m.MarshalJSONBuf(&buf); err != nil {
+		d.logger.Error("failed to render dead letter message", zap.Error(err))
+		return
+	}
+
+	line, err := json.Marshal(DeadLetterRecord{Message: buf.Bytes(), Reason: reason.Error(), Time: time.Now().UTC()})
+
+	if err != nil {
+		d.logger.Error("failed to render dead letter record", zap.Error(err))
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if err := d.write(append(line, '\n')); err != nil {
+		d.logger.Error("failed to write dead letter", zap.String("path", d.config.Path), zap.Error(err))
+	}
+}
+
+// WriteLogs writes the messages rendered from the logs with the reason they could not be delivered.
+func (d *DeadLetter) WriteLogs(ld plog.Logs, reason error) {
+	if d == nil {
+		return
+	}
+
+	for _, m := range d.render(ld) {
+		d.Write(m, reason)
+	}
+}
+
+// Close closes the dead letter file.
+func (d *DeadLetter) Close() error {
+	if d == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+
+	err := d.file.Close()
+	d.file = nil
+
+	return err
+}
+
+func (d *DeadLetter) write(line []byte) error {
+	if d.file != nil && d.size > 0 && d.size+int64(len(line)) > d.config.MaxSize {
+		if err := d.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if d.file == nil {
+		if err := d.open(); err != nil {
+			return err
+		}
+	}
+
+	n, err := d.file.Write(line)
+	d.size += int64(n)
+
+	return err
+}
+
+func (d *DeadLetter) open() error {
+	if err := os.MkdirAll(filepath.Dir(d.config.Path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(d.config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	d.file = file
+	d.size = info.Size()
+
+	return nil
+}
+
+// rotate closes the file and shifts it and the rotated files by one, dropping the oldest one.
+func (d *DeadLetter) rotate() error {
+	if err := d.file.Close(); err != nil {
+		return err
+	}
+
+	d.file = nil
+
+	if err := os.Remove(d.backupPath(d.config.MaxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for i := d.config.MaxBackups - 1; i >= 0; i-- {
+		if err := os.Rename(d.backupPath(i), d.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backupPath returns the path of the i-th rotated file, where 0 is the current file.
+func (d *DeadLetter) backupPath(i int) string {
+	if i == 0 {
+		return d.config.Path
+	}
+
+	return fmt.Sprintf("%s.%d", d.config.Path, i)
+}
+
+// ReplayDeadLetter re-sends the messages of a dead letter file to the writer, returning the number of messages sent.
+// It stops at the first message that fails, so that the replay can be resumed from it.
+func ReplayDeadLetter(r io.Reader, w gelf.Writer) (int, error) {
+	var sent int
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		var record DeadLetterRecord
+		var m gelf.Message
+
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return sent, fmt.Errorf("invalid dead letter record on line %d: %w", line, err)
+		}
+
+		if err := m.UnmarshalJSON(record.Message); err != nil {
+			return sent, fmt.Errorf("invalid GELF message on line %d: %w", line, err)
+		}
+
+		if err := w.WriteMessage(&m); err != nil {
+			return sent, fmt.Errorf("failed to send message on line %d: %w", line, err)
+		}
+
+		sent++
+	}
+
+	return sent, scanner.Err()
+}
@@ -0,0 +1,154 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestDeadLetter(t *testing.T, maxSize int64, maxBackups int) (*DeadLetter, string) {
+	path := filepath.Join(t.TempDir(), "dead", "letter.log")
+	cfg := &DeadLetterConfig{Enabled: true, MaxBackups: maxBackups, MaxSize: maxSize, Path: path}
+
+	require.NoError(t, cfg.Validate())
+
+	return NewDeadLetter(cfg, renderTestMessages, zap.NewNop()), path
+}
+
+func renderTestMessages(ld plog.Logs) []*gelf.Message {
+	var messages []*gelf.Message
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		lr := ld.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords()
+
+		for j := 0; j < lr.Len(); j++ {
+			messages = append(messages, &gelf.Message{Version: "1.1", Host: "test", Short: lr.At(j).Body().AsString()})
+		}
+	}
+
+	return messages
+}
+
+func replayFile(t *testing.T, path string, w *recordingWriter) (int, error) {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	return ReplayDeadLetter(file, w)
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	d, path := newTestDeadLetter(t, DefaultDeadLetterMaxSize, DefaultDeadLetterMaxBackups)
+
+	d.Write(&gelf.Message{Version: "1.1", Host: "test", Short: "too large"}, errors.New("msg too large"))
+	d.WriteLogs(newBodyLogs("first", "second"), errors.New("no more retries left"))
+	require.NoError(t, d.Close())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"reason":"msg too large"`)
+
+	w := newRecordingWriter()
+	sent, err := replayFile(t, path, w)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, sent)
+	assert.Equal(t, []string{"too large", "first", "second"}, w.recording.messages())
+
+	// The replay stops at the first failing message, so that it can be resumed from it.
+	w = newRecordingWriter()
+	w.limit = 1
+	sent, err = replayFile(t, path, w)
+
+	assert.ErrorContains(t, err, "failed to send message on line 2")
+	assert.Equal(t, 1, sent)
+}
+
+func TestDeadLetterRotation(t *testing.T) {
+	d, path := newTestDeadLetter(t, 150, 1)
+
+	for _, body := range []string{"first", "second", "third"} {
+		d.WriteLogs(newBodyLogs(body), errors.New("connection refused"))
+	}
+
+	require.NoError(t, d.Close())
+
+	files, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{path, path + ".1"}, files)
+
+	for file, want := range map[string][]string{path: {"third"}, path + ".1": {"second"}} {
+		w := newRecordingWriter()
+		_, err := replayFile(t, file, w)
+
+		require.NoError(t, err)
+		assert.Equal(t, want, w.recording.messages(), file)
+	}
+}
+
+func TestNewLogsDeadLetter(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []string
+	}{
+		{
+			name: "RetriesExhausted",
+			err:  errors.New("connection refused"),
+			want: []string{"first", "second"},
+		},
+		{
+			name: "UnsentLogs",
+			err:  consumererror.NewLogs(errors.New("connection refused"), newBodyLogs("second")),
+			want: []string{"second"},
+		},
+		{
+			name: "Permanent",
+			err:  consumererror.NewPermanent(errors.New("msg too large")),
+		},
+		{
+			name: "PermanentUnsentLogs",
+			err:  consumererror.NewPermanent(consumererror.NewLogs(errors.New("connection refused"), newBodyLogs("second"))),
+			want: []string{"second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, path := newTestDeadLetter(t, DefaultDeadLetterMaxSize, DefaultDeadLetterMaxBackups)
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.QueueConfig.Enabled = false
+			cfg.RetryConfig.Enabled = false
+
+			e, err := NewLogs(context.Background(), exportertest.NewNopSettings(component.MustNewType(UdpExporterType)), cfg, cfg, d,
+				func(context.Context, plog.Logs) error { return tt.err })
+			require.NoError(t, err)
+			require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+
+			assert.Error(t, e.ConsumeLogs(context.Background(), newBodyLogs("first", "second")))
+			require.NoError(t, e.Shutdown(context.Background()))
+			require.NoError(t, d.Close())
+
+			w := newRecordingWriter()
+
+			if _, err := os.Stat(path); err == nil {
+				_, err = replayFile(t, path, w)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, w.recording.messages())
+		})
+	}
+}
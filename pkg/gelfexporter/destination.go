@@ -0,0 +1,262 @@
+package gelfexporter
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"sync"
+	"time"
+)
+
+// Destination writes messages to the primary endpoints, failing over to FailoverEndpoints in order
+// when the active endpoints fail to initialize or keep failing writes.
+// While a failover endpoint is active, the primary endpoints are probed in the background
+// and traffic switches back once they are available again.
+// Initializing, refreshing and failing over are serialized by switchLock, so that concurrent writes
+// keep using the active endpoints meanwhile instead of waiting for them.
+type Destination struct {
+	active     int
+	closed     bool
+	config     *FailoverConfig
+	failures   int
+	fallback   *Fallback
+	groups     []*Balancer
+	lock       sync.Mutex
+	logger     *zap.Logger
+	probing    bool
+	stop       chan struct{}
+	stopOnce   sync.Once
+	switchLock sync.Mutex
+	wg         sync.WaitGroup
+}
+
+func NewDestination(cfg *Config, dialer Dialer, logger *zap.Logger) *Destination {
+	d := &Destination{
+		config: &cfg.Failover,
+		groups: []*Balancer{NewBalancer(cfg, cfg.EndpointConfigs(), dialer, logger)},
+		logger: logger,
+		stop:   make(chan struct{}),
+	}
+
+	for _, endpoint := range cfg.FailoverEndpoints {
+		d.groups = append(d.groups, NewBalancer(cfg, []EndpointConfig{{Endpoint: endpoint, Weight: 1}}, dialer, logger))
+	}
+
+	return d
+}
+
+// Fallback is an alternative transport used after all endpoints of a destination have failed.
+type Fallback struct {
+	// Dialer creates the GELF writers of the fallback transport.
+	Dialer Dialer
+
+	// Endpoint is the address used by the fallback transport.
+	// If empty, the primary endpoints of the destination are used.
+	Endpoint string
+
+	// OnTransition is called with the transport in use after each switch to or from the fallback transport.
+	OnTransition func(transport string)
+
+	// PrimaryTransport is the name of the transport of the endpoints of the destination.
+	PrimaryTransport string
+
+	// Transport is the name of the fallback transport.
+	Transport string
+}
+
+// addFallback adds the fallback transport after the failover endpoints of the destination.
+func (d *Destination) addFallback(cfg *Config, fallback *Fallback) {
+	endpoints := cfg.EndpointConfigs()
+
+	if fallback.Endpoint != "" {
+		endpoints = []EndpointConfig{{Endpoint: fallback.Endpoint, Weight: 1}}
+	}
+
+	d.fallback = fallback
+	d.groups = append(d.groups, NewBalancer(cfg, endpoints, fallback.Dialer, d.logger.With(zap.String("transport", fallback.Transport))))
+}
+
+// Init initializes the primary endpoints, failing over to the next endpoints if that fails.
+func (d *Destination) Init() bool {
+	d.switchLock.Lock()
+	defer d.switchLock.Unlock()
+
+	for i, group := range d.groups {
+		if group.Init() {
+			d.switchTo(i)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Refresh refreshes the active endpoints, failing over to the next endpoints if that fails.
+func (d *Destination) Refresh() bool {
+	d.switchLock.Lock()
+	defer d.switchLock.Unlock()
+
+	active := d.activeIndex()
+
+	if d.groups[active].Refresh() {
+		return true
+	}
+
+	return d.failover(active)
+}
+
+// WriteMessage writes the message with the routing key to the active endpoints.
+// After FailoverConfig.WriteFailureThreshold consecutive failures it fails over and writes the message again.
+func (d *Destination) WriteMessage(m *gelf.Message, key string) error {
+	active := d.activeIndex()
+	err := d.groups[active].WriteMessage(m, key)
+
+	d.lock.Lock()
+
+	if err == nil {
+		d.failures = 0
+		d.lock.Unlock()
+
+		return nil
+	}
+
+	d.failures++
+	failing := d.failures >= d.config.WriteFailureThreshold && len(d.groups) > 1
+	d.lock.Unlock()
+
+	if !failing {
+		return err
+	}
+
+	d.switchLock.Lock()
+
+	// Another write may have failed over from the same endpoints while this one waited for the lock.
+	switched := d.activeIndex() != active || d.failover(active)
+
+	d.switchLock.Unlock()
+
+	if !switched {
+		return err
+	}
+
+	return d.groups[d.activeIndex()].WriteMessage(m, key)
+}
+
+// Close stops probing the primary endpoints and closes the connections of all endpoints.
+func (d *Destination) Close() error {
+	var errs []error
+
+	// Probing is only started under the lock until the destination is closed, so that no probe is added while waiting.
+	d.lock.Lock()
+	d.closed = true
+	d.lock.Unlock()
+
+	d.stopOnce.Do(func() { close(d.stop) })
+	d.wg.Wait()
+
+	for _, group := range d.groups {
+		if err := group.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// failover switches to the first endpoints after the failed ones that initialize successfully.
+// It must be called with switchLock held.
+func (d *Destination) failover(failed int) bool {
+	for i := failed + 1; i < len(d.groups); i++ {
+		d.logger.Warn(fmt.Sprintf("failing over to endpoints %s", d.groups[i]))
+
+		if d.groups[i].Init() {
+			d.switchTo(i)
+			return true
+		}
+	}
+
+	d.logger.Error("no failover endpoint available")
+
+	return false
+}
+
+func (d *Destination) activeIndex() int {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.active
+}
+
+func (d *Destination) switchTo(i int) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.activate(i)
+}
+
+func (d *Destination) activate(i int) {
+	if i != d.active {
+		d.logger.Warn(fmt.Sprintf("switched from endpoints %s to %s", d.groups[d.active], d.groups[i]))
+	}
+
+	if d.isFallback(i) != d.isFallback(d.active) {
+		from, to := d.fallback.PrimaryTransport, d.fallback.Transport
+
+		if !d.isFallback(i) {
+			from, to = to, from
+		}
+
+		d.logger.Warn(fmt.Sprintf("switched from %s to %s transport", from, to))
+
+		if d.fallback.OnTransition != nil {
+			d.fallback.OnTransition(to)
+		}
+	}
+
+	d.active = i
+	d.failures = 0
+
+	if d.active != 0 && !d.probing && !d.closed {
+		d.probing = true
+		d.wg.Add(1)
+
+		go d.probe()
+	}
+}
+
+// isFallback reports whether the endpoints at the index use the fallback transport.
+func (d *Destination) isFallback(i int) bool {
+	return d.fallback != nil && i == len(d.groups)-1
+}
+
+// probe periodically checks the primary endpoints and switches back to them once they are available.
+func (d *Destination) probe() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(time.Duration(d.config.ProbeInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if !d.groups[0].Probe() {
+				d.logger.Debug("primary endpoints are still unavailable")
+				continue
+			}
+
+			d.switchLock.Lock()
+			d.lock.Lock()
+			d.activate(0)
+			d.probing = false
+			d.lock.Unlock()
+			d.switchLock.Unlock()
+
+			d.logger.Info("switched back to primary endpoints")
+
+			return
+		}
+	}
+}
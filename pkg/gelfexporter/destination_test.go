@@ -0,0 +1,153 @@
+package gelfexporter
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestDestinationConfig() *Config {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "10.0.0.1:12201"
+	cfg.FailoverEndpoints = []string{"10.0.0.2:12201", "10.0.0.3:12201"}
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointInitRetries = 1
+	cfg.Failover.ProbeInterval = 1
+	cfg.Failover.WriteFailureThreshold = 2
+
+	return cfg
+}
+
+func TestDestinationFailoverOnInit(t *testing.T) {
+	var primaryDown atomic.Bool
+
+	primaryDown.Store(true)
+
+	dialer, messages := testDialer()
+	d := NewDestination(newTestDestinationConfig(), func(address string) (gelf.Writer, error) {
+		if address == "10.0.0.1:12201" && primaryDown.Load() {
+			return nil, errors.New("connection refused")
+		}
+
+		return dialer(address)
+	}, zap.NewNop())
+
+	defer func() {
+		require.NoError(t, d.Close())
+	}()
+
+	require.True(t, d.Init())
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+	assert.Equal(t, []string{"10.0.0.2:12201"}, messages.addresses())
+
+	primaryDown.Store(false)
+
+	require.Eventually(t, func() bool {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		return d.active == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+	assert.Equal(t, []string{"10.0.0.2:12201", "10.0.0.1:12201"}, messages.addresses())
+}
+
+func TestDestinationFailoverOnWriteFailures(t *testing.T) {
+	dialer, messages := testDialer("10.0.0.1:12201")
+	d := NewDestination(newTestDestinationConfig(), dialer, zap.NewNop())
+
+	defer func() {
+		require.NoError(t, d.Close())
+	}()
+
+	require.True(t, d.Init())
+	require.Error(t, d.WriteMessage(&gelf.Message{}, ""))
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+
+	assert.Equal(t, []string{"10.0.0.2:12201", "10.0.0.2:12201"}, messages.addresses())
+}
+
+func TestDestinationNoEndpointAvailable(t *testing.T) {
+	d := NewDestination(newTestDestinationConfig(), func(address string) (gelf.Writer, error) {
+		return nil, errors.New("connection refused")
+	}, zap.NewNop())
+
+	defer func() {
+		require.NoError(t, d.Close())
+	}()
+
+	assert.False(t, d.Init())
+}
+
+func TestDestinationFallback(t *testing.T) {
+	var primaryDown atomic.Bool
+	var transitions []string
+
+	primaryDown.Store(true)
+
+	cfg := newTestDestinationConfig()
+	cfg.FailoverEndpoints = nil
+
+	dialer, messages := testDialer()
+	d := NewDestination(cfg, func(address string) (gelf.Writer, error) {
+		if primaryDown.Load() {
+			return nil, errors.New("connection refused")
+		}
+
+		return dialer(address)
+	}, zap.NewNop())
+
+	d.addFallback(cfg, &Fallback{
+		Dialer: func(address string) (gelf.Writer, error) {
+			return dialer("udp://" + address)
+		},
+		OnTransition: func(transport string) {
+			transitions = append(transitions, transport)
+		},
+		PrimaryTransport: "tcp",
+		Transport:        "udp",
+	})
+
+	defer func() {
+		require.NoError(t, d.Close())
+	}()
+
+	require.True(t, d.Init())
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+
+	primaryDown.Store(false)
+
+	require.Eventually(t, func() bool {
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		return d.active == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, d.WriteMessage(&gelf.Message{}, ""))
+
+	assert.Equal(t, []string{"udp://10.0.0.1:12201", "10.0.0.1:12201"}, messages.addresses())
+	assert.Equal(t, []string{"udp", "tcp"}, transitions)
+}
+
+func TestDestinationDoesNotProbeAfterClose(t *testing.T) {
+	dialer, _ := testDialer()
+	d := NewDestination(newTestDestinationConfig(), dialer, zap.NewNop())
+
+	require.True(t, d.Init())
+	require.NoError(t, d.Close())
+
+	d.switchTo(1)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	assert.False(t, d.probing)
+}
@@ -0,0 +1,170 @@
+package gelfexporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ConnectionError is a failure to connect or write to the GELF input, such as a refused or reset connection.
+// It is retryable, as the GELF input is expected to become available again.
+type ConnectionError struct {
+	Err error
+}
+
+func (e *ConnectionError) Error() string {
+	return "GELF input unavailable: " + e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// TLSVerificationError is a failure to verify the certificate of the GELF input.
+// It is permanent, as it persists until the certificate or the TLS settings are fixed.
+type TLSVerificationError struct {
+	Err error
+}
+
+func (e *TLSVerificationError) Error() string {
+	return "GELF input certificate verification failed: " + e.Err.Error()
+}
+
+func (e *TLSVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// MessageTooLargeError is a failure to write a message exceeding the size limits of the transport,
+// such as the number of GELF UDP chunks.
+// It is permanent, as the message fails the same way on every retry.
+type MessageTooLargeError struct {
+	Err error
+}
+
+func (e *MessageTooLargeError) Error() string {
+	return "GELF message too large: " + e.Err.Error()
+}
+
+func (e *MessageTooLargeError) Unwrap() error {
+	return e.Err
+}
+
+// CircuitOpenError is a rejection of a batch by the circuit breaker of a route while its endpoints are down.
+// It is retryable, as the circuit lets batches through again after its cool-down.
+type CircuitOpenError struct {
+	Route string
+
+	// RetryAfter is the remaining cool-down, or zero if a trial batch is already in progress.
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit of route %s is open", e.Route)
+}
+
+// RetryAfterCoolDown returns the CircuitOpenError of a rejected batch as a throttled error,
+// so that the batch is retried once the cool-down ends rather than after the retry backoff.
+// Any other error, including nil, is returned as is.
+func RetryAfterCoolDown(err error) error {
+	var openErr *CircuitOpenError
+
+	if !errors.As(err, &openErr) || openErr.RetryAfter <= 0 {
+		return err
+	}
+
+	return exporterhelper.NewThrottleRetry(err, openErr.RetryAfter)
+}
+
+// RateLimitError is a failure to wait for the rate limit to allow a message before the batch timed out.
+// It is retryable, and it doesn't count against the circuit breaker, as the endpoints are not at fault.
+type RateLimitError struct {
+	Err error
+	Key string
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Key == "" {
+		return "rate limit exceeded: " + e.Err.Error()
+	}
+
+	return fmt.Sprintf("rate limit of key %q exceeded: %s", e.Key, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// InflightLimitError is a refusal of a batch whose next chunk of log records exceeds the in-flight memory limit.
+// It is retryable, and it doesn't count against the circuit breaker, as the endpoints are not at fault.
+type InflightLimitError struct {
+	Limit int64
+}
+
+func (e *InflightLimitError) Error() string {
+	return fmt.Sprintf("in-flight limit of %d bytes exceeded", e.Limit)
+}
+
+// ClassifyError wraps the error of connecting or writing to the GELF inputs into the type of its class.
+// TLSVerificationError and MessageTooLargeError are marked permanent, so that they are not retried,
+// and any other error is a ConnectionError. An error joining the errors of several endpoints
+// is only permanent if the errors of all endpoints are of the same permanent class.
+func ClassifyError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case matchesAll(err, isTLSVerificationError):
+		return consumererror.NewPermanent(&TLSVerificationError{Err: err})
+	case matchesAll(err, isMessageTooLargeError):
+		return consumererror.NewPermanent(&MessageTooLargeError{Err: err})
+	}
+
+	return &ConnectionError{Err: err}
+}
+
+// matchesAll reports whether the error or an error it wraps matches,
+// requiring all errors to match if it joins several ones.
+func matchesAll(err error, match func(error) bool) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			if !matchesAll(e, match) {
+				return false
+			}
+		}
+
+		return len(joined.Unwrap()) > 0
+	}
+
+	if match(err) {
+		return true
+	}
+
+	if wrapped := errors.Unwrap(err); wrapped != nil {
+		return matchesAll(wrapped, match)
+	}
+
+	return false
+}
+
+func isTLSVerificationError(err error) bool {
+	switch err.(type) {
+	case *tls.CertificateVerificationError, x509.CertificateInvalidError, x509.HostnameError, x509.UnknownAuthorityError:
+		return true
+	}
+
+	return false
+}
+
+// isMessageTooLargeError matches the errors of the GELF writers by their message, as they don't wrap the cause.
+func isMessageTooLargeError(err error) bool {
+	if err == syscall.EMSGSIZE {
+		return true
+	}
+
+	return strings.Contains(err.Error(), "msg too large") || strings.Contains(err.Error(), syscall.EMSGSIZE.Error())
+}
@@ -0,0 +1,84 @@
+package gelfexporter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	tlsErr := fmt.Errorf("failed to start TLS gateway: %w", &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}})
+	refusedErr := fmt.Errorf("dial tcp 10.0.0.1:12201: %w", syscall.ECONNREFUSED)
+
+	tests := []struct {
+		name      string
+		err       error
+		target    any
+		permanent bool
+	}{
+		{
+			name:   "ConnectionRefused",
+			err:    refusedErr,
+			target: new(*ConnectionError),
+		},
+		{
+			name:      "TLSVerification",
+			err:       fmt.Errorf("failed to initialize GELF writer for endpoint graylog:12201: %w", tlsErr),
+			target:    new(*TLSVerificationError),
+			permanent: true,
+		},
+		{
+			name:      "MessageTooLarge",
+			err:       errors.New("msg too large, would need 200 chunks"),
+			target:    new(*MessageTooLargeError),
+			permanent: true,
+		},
+		{
+			name:      "MessageTooLongForSocket",
+			err:       fmt.Errorf("write udp 10.0.0.1:12201: %w", syscall.EMSGSIZE),
+			target:    new(*MessageTooLargeError),
+			permanent: true,
+		},
+		{
+			name:   "SomeEndpointsRefused",
+			err:    errors.Join(tlsErr, refusedErr),
+			target: new(*ConnectionError),
+		},
+		{
+			name:      "AllEndpointsFailingVerification",
+			err:       errors.Join(tlsErr, tlsErr),
+			target:    new(*TLSVerificationError),
+			permanent: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ClassifyError(tt.err)
+
+			assert.ErrorAs(t, err, tt.target)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.permanent, consumererror.IsPermanent(err))
+		})
+	}
+
+	assert.NoError(t, ClassifyError(nil))
+}
+
+func TestRetryAfterCoolDown(t *testing.T) {
+	openErr := &CircuitOpenError{Route: DefaultRouteName, RetryAfter: time.Minute}
+	trialErr := &CircuitOpenError{Route: DefaultRouteName}
+	refusedErr := errors.New("connection refused")
+
+	assert.Equal(t, exporterhelper.NewThrottleRetry(openErr, time.Minute), RetryAfterCoolDown(openErr))
+	assert.Equal(t, trialErr, RetryAfterCoolDown(trialErr))
+	assert.Equal(t, refusedErr, RetryAfterCoolDown(refusedErr))
+	assert.NoError(t, RetryAfterCoolDown(nil))
+}
@@ -1,11 +1,55 @@
 package gelfexporter
 
 import (
+	"errors"
+	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// EndpointSRVScheme is the prefix of endpoints discovered through DNS SRV records.
+const EndpointSRVScheme = "srv://"
+
+var (
+	lookupIP  = net.LookupIP
+	lookupSRV = net.LookupSRV
+)
+
+// ResolveEndpoint resolves the endpoint host into the first usable IP address, keeping the port if present.
+//
+// Deprecated: use Resolver.ResolveEndpoint, which uses the resolver configuration of the exporter.
 func ResolveEndpoint(endpoint string) (string, error) {
+	endpoints, err := ResolveEndpoints(endpoint, IPFamilyAny)
+
+	if err != nil {
+		return "", err
+	}
+
+	return endpoints[0], nil
+}
+
+// ResolveEndpoints resolves the endpoint host into all IP addresses matching the IP family,
+// ordered in which connection attempts should be made, keeping the port if present.
+// Endpoints prefixed with "srv://" are discovered through DNS SRV records.
+//
+// Deprecated: use Resolver.Resolve, which uses the resolver configuration of the exporter.
+func ResolveEndpoints(endpoint string, ipFamily string) ([]string, error) {
+	endpoints, _, err := resolveEndpoints(systemResolver{}, endpoint, ipFamily)
+	return endpoints, err
+}
+
+// IsSRVEndpoint reports whether the endpoint is discovered through DNS SRV records.
+func IsSRVEndpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, EndpointSRVScheme)
+}
+
+func resolveEndpoints(r resolver, endpoint string, ipFamily string) ([]string, time.Duration, error) {
+	if IsSRVEndpoint(endpoint) {
+		return resolveSRVEndpoints(r, strings.TrimPrefix(endpoint, EndpointSRVScheme), ipFamily)
+	}
+
 	var err error
 	var host = endpoint
 	var port = ""
@@ -14,19 +58,117 @@ func ResolveEndpoint(endpoint string) (string, error) {
 		host, port, err = net.SplitHostPort(endpoint)
 
 		if err != nil {
-			return "", err
+			return nil, 0, err
 		}
 	}
 
-	ips, err := net.LookupIP(host)
+	ips, ttl, err := r.LookupIP(host)
 
-	if err != nil || ips == nil || len(ips) == 0 {
-		return "", err
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ips = OrderIPs(ips, ipFamily)
+
+	if len(ips) == 0 {
+		return nil, 0, fmt.Errorf("no %s address found for %s", ipFamily, host)
+	}
+
+	endpoints := make([]string, 0, len(ips))
+
+	for _, ip := range ips {
+		if port != "" {
+			endpoints = append(endpoints, net.JoinHostPort(ip.String(), port))
+		} else {
+			endpoints = append(endpoints, ip.String())
+		}
+	}
+
+	return endpoints, ttl, nil
+}
+
+// resolveSRVEndpoints looks up SRV records of the name and resolves their targets.
+// Targets are tried in order of priority and, within the same priority, in a weighted random order (RFC 2782).
+func resolveSRVEndpoints(r resolver, name string, ipFamily string) ([]string, time.Duration, error) {
+	records, ttl, err := r.LookupSRV(name)
+
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if port != "" {
-		return net.JoinHostPort(ips[0].String(), port), nil
+	var endpoints []string
+	var errs []error
+
+	for _, record := range records {
+		target := net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+		resolved, targetTTL, err := resolveEndpoints(r, target, ipFamily)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		endpoints = append(endpoints, resolved...)
+		ttl = minTTL(ttl, targetTTL)
+	}
+
+	if len(endpoints) == 0 {
+		if len(errs) > 0 {
+			return nil, 0, errors.Join(errs...)
+		}
+
+		return nil, 0, fmt.Errorf("no SRV target found for %s", name)
+	}
+
+	return endpoints, ttl, nil
+}
+
+// OrderIPs filters and orders IP addresses according to the IP family.
+// For "any" the families are interleaved, starting with the family of the first address,
+// so that a connection attempt to the other family follows quickly (RFC 8305).
+func OrderIPs(ips []net.IP, ipFamily string) []net.IP {
+	var v4, v6 []net.IP
+
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			v4 = append(v4, ip)
+		} else {
+			v6 = append(v6, ip)
+		}
+	}
+
+	switch ipFamily {
+	case IPFamilyIPv4:
+		return v4
+	case IPFamilyIPv6:
+		return v6
+	case IPFamilyPreferIPv4:
+		return append(v4, v6...)
+	case IPFamilyPreferIPv6:
+		return append(v6, v4...)
+	}
+
+	if len(ips) == 0 {
+		return nil
+	}
+
+	first, second := v4, v6
+
+	if ips[0].To4() == nil {
+		first, second = v6, v4
+	}
+
+	ordered := make([]net.IP, 0, len(ips))
+
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			ordered = append(ordered, first[i])
+		}
+
+		if i < len(second) {
+			ordered = append(ordered, second[i])
+		}
 	}
 
-	return ips[0].String(), nil
+	return ordered
 }
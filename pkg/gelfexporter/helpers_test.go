@@ -0,0 +1,305 @@
+package gelfexporter
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// testRecord describes a log record of the logs built by newTestLogs.
+type testRecord struct {
+	attributes   map[string]string
+	body         string
+	severity     plog.SeverityNumber
+	severityText string
+}
+
+// testResource describes a resource of the logs built by newTestLogs, with a single scope holding its log records.
+type testResource struct {
+	attributes map[string]string
+	records    []testRecord
+}
+
+// testScopeName is the name of the scope of the resources of the logs built by newTestLogs.
+const testScopeName = "scope"
+
+// newTestLogs returns the logs of the resources.
+func newTestLogs(resources ...testResource) plog.Logs {
+	ld := plog.NewLogs()
+
+	for _, resource := range resources {
+		rl := ld.ResourceLogs().AppendEmpty()
+
+		for key, value := range resource.attributes {
+			rl.Resource().Attributes().PutStr(key, value)
+		}
+
+		sl := rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName(testScopeName)
+
+		for _, record := range resource.records {
+			lr := sl.LogRecords().AppendEmpty()
+			lr.Body().SetStr(record.body)
+			lr.SetSeverityNumber(record.severity)
+			lr.SetSeverityText(record.severityText)
+
+			for key, value := range record.attributes {
+				lr.Attributes().PutStr(key, value)
+			}
+		}
+	}
+
+	return ld
+}
+
+// newBodyLogs returns logs with a single resource holding a log record for each body.
+func newBodyLogs(bodies ...string) plog.Logs {
+	return newTestLogs(testResource{records: bodyRecords(bodies...)})
+}
+
+// bodyRecords returns a log record for each body.
+func bodyRecords(bodies ...string) []testRecord {
+	records := make([]testRecord, 0, len(bodies))
+
+	for _, body := range bodies {
+		records = append(records, testRecord{body: body})
+	}
+
+	return records
+}
+
+// numberedRecords returns the given number of log records, with their index as body.
+func numberedRecords(count int) []testRecord {
+	records := make([]testRecord, 0, count)
+
+	for i := 0; i < count; i++ {
+		records = append(records, testRecord{body: strconv.Itoa(i)})
+	}
+
+	return records
+}
+
+// resourcesOf returns a resource for each value of the resource attribute, each holding the log records.
+func resourcesOf(attribute string, values []string, records ...testRecord) []testResource {
+	resources := make([]testResource, 0, len(values))
+
+	for _, value := range values {
+		resources = append(resources, testResource{attributes: map[string]string{attribute: value}, records: records})
+	}
+
+	return resources
+}
+
+// recordedMessage is a short message recorded by a recording writer, with the address of the writer.
+type recordedMessage struct {
+	address string
+	short   string
+}
+
+// recording holds the messages written by the recording writers sharing it, in order.
+type recording struct {
+	lock     sync.Mutex
+	recorded []recordedMessage
+}
+
+// addresses returns the addresses of the writers of the recorded messages.
+func (r *recording) addresses() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var addresses []string
+
+	for _, m := range r.recorded {
+		addresses = append(addresses, m.address)
+	}
+
+	return addresses
+}
+
+// messages returns the recorded short messages.
+func (r *recording) messages() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var messages []string
+
+	for _, m := range r.recorded {
+		messages = append(messages, m.short)
+	}
+
+	return messages
+}
+
+// recordingWriter records the messages written to it, failing every write if failing is set,
+// or the writes beyond limit recorded messages if limit is set.
+type recordingWriter struct {
+	gelf.Writer
+	address   string
+	failing   bool
+	limit     int
+	recording *recording
+}
+
+func newRecordingWriter() *recordingWriter {
+	return &recordingWriter{recording: &recording{}}
+}
+
+func (w *recordingWriter) WriteMessage(m *gelf.Message) error {
+	if w.failing {
+		return errors.New("connection refused")
+	}
+
+	w.recording.lock.Lock()
+	defer w.recording.lock.Unlock()
+
+	if w.limit > 0 && len(w.recording.recorded) == w.limit {
+		return errors.New("connection refused")
+	}
+
+	w.recording.recorded = append(w.recording.recorded, recordedMessage{address: w.address, short: m.Short})
+
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	return nil
+}
+
+// testDialer returns a dialer of recording writers sharing a recording, failing for the given addresses.
+func testDialer(failing ...string) (Dialer, *recording) {
+	r := &recording{}
+
+	return func(address string) (gelf.Writer, error) {
+		w := &recordingWriter{address: address, recording: r}
+
+		for _, f := range failing {
+			w.failing = w.failing || f == address
+		}
+
+		return w, nil
+	}, r
+}
+
+func TestOrderIPs(t *testing.T) {
+	v4a := net.ParseIP("10.0.0.1")
+	v4b := net.ParseIP("10.0.0.2")
+	v6a := net.ParseIP("fd00::1")
+	v6b := net.ParseIP("fd00::2")
+
+	tests := []struct {
+		name     string
+		ips      []net.IP
+		ipFamily string
+		expected []net.IP
+	}{
+		{
+			name:     "AnyInterleavedFromIPv6",
+			ips:      []net.IP{v6a, v6b, v4a, v4b},
+			ipFamily: IPFamilyAny,
+			expected: []net.IP{v6a, v4a, v6b, v4b},
+		},
+		{
+			name:     "AnyInterleavedFromIPv4",
+			ips:      []net.IP{v4a, v6a, v6b},
+			ipFamily: IPFamilyAny,
+			expected: []net.IP{v4a, v6a, v6b},
+		},
+		{
+			name:     "IPv4Only",
+			ips:      []net.IP{v6a, v4a, v6b, v4b},
+			ipFamily: IPFamilyIPv4,
+			expected: []net.IP{v4a, v4b},
+		},
+		{
+			name:     "IPv6Only",
+			ips:      []net.IP{v6a, v4a, v6b, v4b},
+			ipFamily: IPFamilyIPv6,
+			expected: []net.IP{v6a, v6b},
+		},
+		{
+			name:     "PreferIPv4",
+			ips:      []net.IP{v6a, v4a, v6b, v4b},
+			ipFamily: IPFamilyPreferIPv4,
+			expected: []net.IP{v4a, v4b, v6a, v6b},
+		},
+		{
+			name:     "PreferIPv6",
+			ips:      []net.IP{v4a, v6a, v4b},
+			ipFamily: IPFamilyPreferIPv6,
+			expected: []net.IP{v6a, v4a, v4b},
+		},
+		{
+			name:     "NoMatchingFamily",
+			ips:      []net.IP{v4a},
+			ipFamily: IPFamilyIPv6,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, OrderIPs(tt.ips, tt.ipFamily))
+		})
+	}
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	endpoints, err := ResolveEndpoints("127.0.0.1:12201", IPFamilyAny)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:12201"}, endpoints)
+
+	endpoints, err = ResolveEndpoints("[::1]:12201", IPFamilyPreferIPv4)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"[::1]:12201"}, endpoints)
+
+	_, err = ResolveEndpoints("127.0.0.1:12201", IPFamilyIPv6)
+	require.EqualError(t, err, "no ipv6 address found for 127.0.0.1")
+}
+
+func TestResolveSRVEndpoints(t *testing.T) {
+	defer func(ip func(string) ([]net.IP, error), srv func(string, string, string) (string, []*net.SRV, error)) {
+		lookupIP, lookupSRV = ip, srv
+	}(lookupIP, lookupSRV)
+
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		assert.Equal(t, "_gelf._tcp.logs.example.com", name)
+
+		return name, []*net.SRV{
+			{Target: "graylog1.example.com.", Port: 12201, Priority: 10, Weight: 5},
+			{Target: "broken.example.com.", Port: 12201, Priority: 10, Weight: 5},
+			{Target: "graylog2.example.com.", Port: 12202, Priority: 20, Weight: 0},
+		}, nil
+	}
+
+	lookupIP = func(host string) ([]net.IP, error) {
+		switch host {
+		case "graylog1.example.com":
+			return []net.IP{net.ParseIP("10.0.0.1")}, nil
+		case "graylog2.example.com":
+			return []net.IP{net.ParseIP("10.0.0.2"), net.ParseIP("fd00::2")}, nil
+		}
+
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+
+	endpoints, err := ResolveEndpoints("srv://_gelf._tcp.logs.example.com", IPFamilyAny)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:12201", "10.0.0.2:12202", "[fd00::2]:12202"}, endpoints)
+
+	endpoints, err = ResolveEndpoints("srv://_gelf._tcp.logs.example.com", IPFamilyIPv6)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"[fd00::2]:12202"}, endpoints)
+
+	lookupSRV = func(service, proto, name string) (string, []*net.SRV, error) {
+		return name, nil, nil
+	}
+
+	_, err = ResolveEndpoints("srv://_gelf._tcp.logs.example.com", IPFamilyAny)
+	require.EqualError(t, err, "no SRV target found for _gelf._tcp.logs.example.com")
+}
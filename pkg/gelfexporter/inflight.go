@@ -0,0 +1,205 @@
+package gelfexporter
+
+import (
+	"context"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"iter"
+	"sync"
+)
+
+// inflightChunkRecords is the number of log records converted and sent at once under the in-flight limit.
+const inflightChunkRecords = 100
+
+// InflightLimiter caps the estimated memory of the log records converted and sent at once by all consumers,
+// estimated from the size of their protobuf encoding.
+// All methods are no-ops on a nil InflightLimiter, which doesn't split batches into chunks.
+type InflightLimiter struct {
+	inflight int64
+	limit    int64
+	lock     sync.Mutex
+}
+
+// NewInflightLimiter returns the in-flight limiter of the exporter, or nil if MaxInflightBytes is not set.
+func NewInflightLimiter(cfg *Config) *InflightLimiter {
+	if cfg.MaxInflightBytes == 0 {
+		return nil
+	}
+
+	return &InflightLimiter{limit: cfg.MaxInflightBytes}
+}
+
+// Chunks returns the chunks of the log records of the logs to be converted and sent one by one,
+// each with the index of its first log record, keeping their resources and scopes.
+// The log records of a chunk are moved out of the logs while it is processed and moved back before
+// the next one, so the logs must not be read until the iteration ends. The log records of read-only
+// logs, shared with other consumers, are copied instead.
+func (l *InflightLimiter) Chunks(ld plog.Logs) iter.Seq2[int, plog.Logs] {
+	return func(yield func(int, plog.Logs) bool) {
+		if l == nil {
+			yield(0, ld)
+			return
+		}
+
+		var offset int
+		var resource plog.ResourceLogs
+		var scope plog.ScopeLogs
+		var sources []plog.LogRecord
+
+		chunk := plog.NewLogs()
+		move := !ld.IsReadOnly()
+
+		// next yields the chunk and moves its log records back to the logs, starting the next chunk.
+		next := func() bool {
+			ok := yield(offset, chunk)
+
+			if move {
+				restoreLogRecords(chunk, sources)
+			}
+
+			chunk, offset, sources = plog.NewLogs(), offset+len(sources), sources[:0]
+			resource, scope = plog.ResourceLogs{}, plog.ScopeLogs{}
+
+			return ok
+		}
+
+		for j := 0; j < ld.ResourceLogs().Len(); j++ {
+			rl := ld.ResourceLogs().At(j)
+			resource = plog.ResourceLogs{}
+
+			for k := 0; k < rl.ScopeLogs().Len(); k++ {
+				sl := rl.ScopeLogs().At(k)
+				scope = plog.ScopeLogs{}
+
+				for m := 0; m < sl.LogRecords().Len(); m++ {
+					if len(sources) == inflightChunkRecords && !next() {
+						return
+					}
+
+					if resource == (plog.ResourceLogs{}) {
+						resource = chunk.ResourceLogs().AppendEmpty()
+						rl.Resource().CopyTo(resource.Resource())
+						resource.SetSchemaUrl(rl.SchemaUrl())
+					}
+
+					if scope == (plog.ScopeLogs{}) {
+						scope = resource.ScopeLogs().AppendEmpty()
+						sl.Scope().CopyTo(scope.Scope())
+						scope.SetSchemaUrl(sl.SchemaUrl())
+					}
+
+					lr := sl.LogRecords().At(m)
+
+					if move {
+						lr.MoveTo(scope.LogRecords().AppendEmpty())
+					} else {
+						lr.CopyTo(scope.LogRecords().AppendEmpty())
+					}
+
+					sources = append(sources, lr)
+				}
+			}
+		}
+
+		if len(sources) > 0 {
+			next()
+		}
+	}
+}
+
+// WriteChunks writes the messages of the logs chunk by chunk under the in-flight limit, rendering each chunk
+// and passing every message admitted by the rate limit to write with the index of its log record,
+// as messages are rendered one per log record, in order.
+// It stops at the first log record refused by a limit or whose write returns an error, returning its index
+// with the error, or the number of log records once all of them were processed. The logs must not be read
+// until it returns, as the log records of a chunk are moved out of them while it is processed.
+func (l *InflightLimiter) WriteChunks(ctx context.Context, ld plog.Logs, limited *RateLimitedLogs,
+	render func(plog.Logs) []*gelf.Message, logger *zap.Logger, write func(i int, m *gelf.Message) error) (int, error) {
+	for offset, chunk := range l.Chunks(ld) {
+		if i, err := l.writeChunk(ctx, offset, chunk, limited, render, logger, write); err != nil {
+			return i, err
+		}
+	}
+
+	return ld.LogRecordCount(), nil
+}
+
+// writeChunk writes the messages of a chunk starting at the offset-th log record, holding the in-flight
+// memory of the chunk meanwhile. It returns the index of the log record it stopped at with the error.
+func (l *InflightLimiter) writeChunk(ctx context.Context, offset int, chunk plog.Logs, limited *RateLimitedLogs,
+	render func(plog.Logs) []*gelf.Message, logger *zap.Logger, write func(i int, m *gelf.Message) error) (int, error) {
+	release, err := l.Acquire(chunk)
+
+	if err != nil {
+		logger.Warn("refusing logs over the in-flight limit", zap.Error(err))
+		return offset, err
+	}
+
+	defer release()
+
+	for k, m := range render(chunk) {
+		admitted, err := limited.Admit(ctx, offset+k, m)
+
+		if err != nil {
+			logger.Warn("failed to wait for rate limit", zap.Error(err))
+			return offset + k, err
+		}
+
+		if !admitted {
+			continue
+		}
+
+		if err := write(offset+k, m); err != nil {
+			return offset + k, err
+		}
+	}
+
+	return offset + chunk.LogRecordCount(), nil
+}
+
+// restoreLogRecords moves the log records of the chunk back to the log records they were moved from, in order.
+func restoreLogRecords(chunk plog.Logs, sources []plog.LogRecord) {
+	var i int
+
+	for j := 0; j < chunk.ResourceLogs().Len(); j++ {
+		rl := chunk.ResourceLogs().At(j)
+
+		for k := 0; k < rl.ScopeLogs().Len(); k++ {
+			lr := rl.ScopeLogs().At(k).LogRecords()
+
+			for m := 0; m < lr.Len(); m++ {
+				lr.At(m).MoveTo(sources[i])
+				i++
+			}
+		}
+	}
+}
+
+// Acquire reserves the estimated memory of the logs, the size of their protobuf encoding,
+// until the returned function is called.
+// It returns an InflightLimitError if that exceeds the limit. Logs larger than the limit
+// are only allowed when nothing else is in flight.
+func (l *InflightLimiter) Acquire(ld plog.Logs) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	size := int64((&plog.ProtoMarshaler{}).LogsSize(ld))
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.inflight > 0 && l.inflight+size > l.limit {
+		return nil, &InflightLimitError{Limit: l.limit}
+	}
+
+	l.inflight += size
+
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.inflight -= size
+	}, nil
+}
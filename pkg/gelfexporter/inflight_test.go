@@ -0,0 +1,244 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/internal/gelftest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"testing"
+)
+
+func TestInflightLimiterChunks(t *testing.T) {
+	tests := []struct {
+		name        string
+		resources   []testResource
+		limiter     *InflightLimiter
+		readOnly    bool
+		wantCounts  []int
+		wantMoved   []int
+		wantOffsets []int
+	}{
+		{
+			name: "Unlimited",
+			resources: []testResource{
+				{attributes: map[string]string{"service.name": "api"}, records: numberedRecords(150)},
+				{attributes: map[string]string{"service.name": "worker"}, records: numberedRecords(100)},
+			},
+			wantCounts:  []int{250},
+			wantMoved:   []int{0},
+			wantOffsets: []int{0},
+		},
+		{
+			name: "Limited",
+			resources: []testResource{
+				{attributes: map[string]string{"service.name": "api"}, records: numberedRecords(150)},
+				{attributes: map[string]string{"service.name": "worker"}, records: numberedRecords(100)},
+			},
+			limiter:     NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+			wantCounts:  []int{100, 100, 50},
+			wantMoved:   []int{100, 100, 50},
+			wantOffsets: []int{0, 100, 200},
+		},
+		{
+			name: "ReadOnly",
+			resources: []testResource{
+				{attributes: map[string]string{"service.name": "api"}, records: numberedRecords(150)},
+				{attributes: map[string]string{"service.name": "worker"}, records: numberedRecords(100)},
+			},
+			limiter:     NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+			readOnly:    true,
+			wantCounts:  []int{100, 100, 50},
+			wantMoved:   []int{0, 0, 0},
+			wantOffsets: []int{0, 100, 200},
+		},
+		{
+			name:    "Empty",
+			limiter: NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var counts, moved, offsets []int
+			var bodies []string
+
+			ld := newTestLogs(tt.resources...)
+			want := gelftest.LogBodies(ld)
+
+			if tt.readOnly {
+				ld.MarkReadOnly()
+			}
+
+			for offset, chunk := range tt.limiter.Chunks(ld) {
+				counts = append(counts, chunk.LogRecordCount())
+				moved = append(moved, movedLogRecords(ld))
+				offsets = append(offsets, offset)
+				bodies = append(bodies, gelftest.LogBodies(chunk)...)
+
+				for i := 0; i < chunk.ResourceLogs().Len(); i++ {
+					_, ok := chunk.ResourceLogs().At(i).Resource().Attributes().Get("service.name")
+					assert.True(t, ok)
+				}
+			}
+
+			assert.Equal(t, tt.wantCounts, counts)
+			assert.Equal(t, tt.wantMoved, moved)
+			assert.Equal(t, tt.wantOffsets, offsets)
+			assert.Equal(t, want, bodies)
+			assert.Equal(t, want, gelftest.LogBodies(ld))
+		})
+	}
+}
+
+func TestInflightLimiterChunksStopped(t *testing.T) {
+	ld := newTestLogs(testResource{records: numberedRecords(250)})
+	want := gelftest.LogBodies(ld)
+
+	for offset := range NewInflightLimiter(&Config{MaxInflightBytes: 1024}).Chunks(ld) {
+		require.Zero(t, offset)
+		break
+	}
+
+	// The log records of the chunk are moved back when the iteration stops.
+	assert.Equal(t, want, gelftest.LogBodies(ld))
+}
+
+// movedLogRecords returns the number of log records of the logs that were moved out, leaving them without a body.
+func movedLogRecords(ld plog.Logs) int {
+	var moved int
+
+	for _, body := range gelftest.LogBodies(ld) {
+		if body == "" {
+			moved++
+		}
+	}
+
+	return moved
+}
+
+func TestInflightLimiterAcquire(t *testing.T) {
+	ld := newTestLogs(testResource{records: numberedRecords(10)})
+	size := int64((&plog.ProtoMarshaler{}).LogsSize(ld))
+
+	l := NewInflightLimiter(&Config{MaxInflightBytes: size + size/2})
+
+	release, err := l.Acquire(ld)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ld)
+	assert.Equal(t, &InflightLimitError{Limit: size + size/2}, err)
+
+	release()
+
+	// Logs larger than the limit are allowed while nothing else is in flight.
+	large := newTestLogs(testResource{records: numberedRecords(20)})
+
+	release, err = l.Acquire(large)
+	require.NoError(t, err)
+
+	_, err = l.Acquire(ld)
+	assert.Error(t, err)
+
+	release()
+
+	release, err = l.Acquire(ld)
+	require.NoError(t, err)
+	release()
+
+	assert.Nil(t, NewInflightLimiter(&Config{}))
+
+	release, err = (*InflightLimiter)(nil).Acquire(large)
+	require.NoError(t, err)
+	release()
+}
+
+func TestInflightLimiterWriteChunks(t *testing.T) {
+	errWrite := errors.New("write failed")
+
+	tests := []struct {
+		name        string
+		limiter     *InflightLimiter
+		failAt      int
+		held        bool
+		wantErr     error
+		wantStop    int
+		wantWritten int
+	}{
+		{
+			name:        "Unlimited",
+			failAt:      -1,
+			wantStop:    250,
+			wantWritten: 250,
+		},
+		{
+			name:        "Limited",
+			limiter:     NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+			failAt:      -1,
+			wantStop:    250,
+			wantWritten: 250,
+		},
+		{
+			name:        "WriteFailed",
+			limiter:     NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+			failAt:      150,
+			wantErr:     errWrite,
+			wantStop:    150,
+			wantWritten: 151,
+		},
+		{
+			name:     "InflightLimitExceeded",
+			limiter:  NewInflightLimiter(&Config{MaxInflightBytes: 1024}),
+			failAt:   -1,
+			held:     true,
+			wantErr:  &InflightLimitError{Limit: 1024},
+			wantStop: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var indexes []int
+
+			ld := newTestLogs(testResource{records: numberedRecords(250)})
+			want := gelftest.LogBodies(ld)
+
+			if tt.held {
+				release, err := tt.limiter.Acquire(newTestLogs(testResource{records: numberedRecords(100)}))
+				require.NoError(t, err)
+				defer release()
+			}
+
+			render := func(chunk plog.Logs) []*gelf.Message {
+				var messages []*gelf.Message
+
+				for _, body := range gelftest.LogBodies(chunk) {
+					messages = append(messages, &gelf.Message{Short: body})
+				}
+
+				return messages
+			}
+
+			stop, err := tt.limiter.WriteChunks(context.Background(), ld, nil, render, zap.NewNop(), func(i int, m *gelf.Message) error {
+				require.Equal(t, want[i], m.Short)
+				indexes = append(indexes, i)
+
+				if i == tt.failAt {
+					return errWrite
+				}
+
+				return nil
+			})
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantStop, stop)
+			assert.Len(t, indexes, tt.wantWritten)
+
+			// The log records of the chunks are moved back once they are written.
+			assert.Equal(t, want, gelftest.LogBodies(ld))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/exporterqueue"
+	"go.opentelemetry.io/collector/pdata/plog"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	"sync/atomic"
+)
+
+// NewLogs returns the logs exporter pushing batches with push through the sending queue, timeout and retries
+// of the configuration. With a dead letter, batches that still fail once their retries are exhausted
+// are written to it. In the priority mode, batches are prioritized before they are queued,
+// and the fill of the sending queue is tracked from the batches taken from it. Both need the retries
+// to happen in an exporter of their own behind the sending queue, as exporterhelper drops batches
+// without reporting them and calls push for every retry.
+func NewLogs(ctx context.Context, set exporter.Settings, cfg component.Config, base *Config, deadLetter *DeadLetter,
+	push consumer.ConsumeLogsFunc, options ...exporterhelper.Option) (exporter.Logs, error) {
+	prioritizer, err := NewPrioritizer(base, set)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if deadLetter == nil && prioritizer == nil {
+		return exporterhelper.NewLogs(ctx, set, cfg, push, append(options,
+			exporterhelper.WithTimeout(base.TimeoutConfig),
+			exporterhelper.WithRetry(base.RetryConfig),
+			exporterhelper.WithQueue(base.QueueConfig),
+		)...)
+	}
+
+	// The batches are only counted once, by the exporter with the sending queue.
+	retrySet := set
+	retrySet.MeterProvider = metricnoop.NewMeterProvider()
+
+	retrying, err := exporterhelper.NewLogs(ctx, retrySet, cfg, push,
+		exporterhelper.WithTimeout(base.TimeoutConfig),
+		exporterhelper.WithRetry(base.RetryConfig),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	e := &queuedLogs{deadLetter: deadLetter, prioritizer: prioritizer, retrying: retrying}
+
+	if e.Logs, err = exporterhelper.NewLogs(ctx, set, cfg, e.pushLogs, append(options,
+		exporterhelper.WithTimeout(exporterhelper.TimeoutConfig{}),
+		exporterhelper.WithQueue(base.QueueConfig),
+	)...); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// queuedLogs is the exporter with the sending queue, prioritizing the batches before they are queued
+// and writing the batches failing in the retrying exporter to the dead letter.
+type queuedLogs struct {
+	exporter.Logs
+	deadLetter   *DeadLetter
+	prioritizer  *Prioritizer
+	retrying     exporter.Logs
+	shuttingDown atomic.Bool
+}
+
+// ConsumeLogs queues the prioritized batch, dropping it if all its log records are shed.
+func (e *queuedLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	if ld = e.prioritizer.Prioritize(ld); ld.LogRecordCount() == 0 {
+		return nil
+	}
+
+	// The batch is counted before it is queued, as it may be taken from the queue before this returns.
+	e.prioritizer.queued()
+
+	err := e.Logs.ConsumeLogs(ctx, ld)
+
+	if err != nil {
+		e.prioritizer.dequeued()
+	}
+
+	if errors.Is(err, exporterqueue.ErrQueueIsFull) {
+		e.prioritizer.rejected(ld)
+	}
+
+	return err
+}
+
+func (e *queuedLogs) Start(ctx context.Context, host component.Host) error {
+	if err := e.retrying.Start(ctx, host); err != nil {
+		return err
+	}
+
+	return e.Logs.Start(ctx, host)
+}
+
+// Shutdown stops the retries before the sending queue is drained, as a single exporter would.
+func (e *queuedLogs) Shutdown(ctx context.Context) error {
+	e.shuttingDown.Store(true)
+
+	return errors.Join(e.retrying.Shutdown(ctx), e.Logs.Shutdown(ctx))
+}
+
+// pushLogs sends a batch taken from the sending queue with retries, writing the logs that were not delivered
+// to the dead letter: the logs of the error if it reports them, permanent or not, and otherwise the whole batch.
+// The dead letter is skipped for permanent errors not reporting logs, which are only returned by failures
+// whose messages were written to the dead letter as they failed, and for retryable errors during the shutdown,
+// as those batches are left to the sending queue, which keeps them if it is persistent.
+func (e *queuedLogs) pushLogs(ctx context.Context, ld plog.Logs) error {
+	e.prioritizer.dequeued()
+
+	err := e.retrying.ConsumeLogs(ctx, ld)
+
+	var logsErr consumererror.Logs
+
+	switch {
+	case err == nil, e.shuttingDown.Load() && !consumererror.IsPermanent(err):
+		return err
+	case errors.As(err, &logsErr):
+		e.deadLetter.WriteLogs(logsErr.Data(), err)
+	case consumererror.IsPermanent(err):
+		break
+	default:
+		e.deadLetter.WriteLogs(ld, err)
+	}
+
+	return err
+}
@@ -0,0 +1,148 @@
+package gelfexporter
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"math/rand/v2"
+	"sync"
+)
+
+// Mirror writes a sample of the messages to its own endpoints in the background.
+// Failures of the mirror are only logged, and messages are dropped while its queue is full.
+type Mirror struct {
+	abort         chan struct{}
+	abortOnce     sync.Once
+	destination   *Destination
+	logger        *zap.Logger
+	messages      chan mirroredMessage
+	name          string
+	samplingRatio float64
+	stop          chan struct{}
+	stopOnce      sync.Once
+	wg            sync.WaitGroup
+}
+
+type mirroredMessage struct {
+	key     string
+	message *gelf.Message
+}
+
+func NewMirror(cfg *Config, mirror *MirrorConfig, dialer Dialer, logger *zap.Logger) *Mirror {
+	var queueSize = DefaultMirrorQueueSize
+	var samplingRatio = 1.0
+
+	if mirror.QueueSize > 0 {
+		queueSize = mirror.QueueSize
+	}
+
+	if mirror.SamplingRatio != nil {
+		samplingRatio = *mirror.SamplingRatio
+	}
+
+	logger = logger.With(zap.String("mirror", mirror.Name))
+
+	return &Mirror{
+		abort:         make(chan struct{}),
+		destination:   NewDestination(cfg.withEndpoints(mirror.Endpoint, mirror.Endpoints, mirror.FailoverEndpoints), dialer, logger),
+		logger:        logger,
+		messages:      make(chan mirroredMessage, queueSize),
+		name:          mirror.Name,
+		samplingRatio: samplingRatio,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start initializes the endpoints of the mirror and starts writing queued messages in the background.
+func (m *Mirror) Start() {
+	m.wg.Add(1)
+
+	go m.run()
+}
+
+// WriteMessage queues a sample of the messages with the routing key to be written to the mirror.
+func (m *Mirror) WriteMessage(message *gelf.Message, key string) {
+	if m.samplingRatio < 1 && rand.Float64() >= m.samplingRatio {
+		return
+	}
+
+	select {
+	case m.messages <- mirroredMessage{key: key, message: message}:
+	default:
+		m.logger.Warn(fmt.Sprintf("queue of mirror %s is full, dropping message", m.name))
+	}
+}
+
+// Shutdown writes the messages still queued until the context is done, then closes the connections of the mirror.
+func (m *Mirror) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+
+	m.stopOnce.Do(func() { close(m.stop) })
+
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return m.destination.Close()
+	case <-ctx.Done():
+	}
+
+	m.logger.Warn(fmt.Sprintf("dropping %d queued message(s) of mirror %s on shutdown", len(m.messages), m.name))
+	m.abortOnce.Do(func() { close(m.abort) })
+
+	// Closing the destination interrupts the initialization of its endpoints, which the mirror may be retrying,
+	// while a message still being written finishes in the background.
+	go func() {
+		if err := m.destination.Close(); err != nil {
+			m.logger.Warn(fmt.Sprintf("failed to close endpoints of mirror %s", m.name), zap.Error(err))
+		}
+	}()
+
+	return nil
+}
+
+func (m *Mirror) run() {
+	defer m.wg.Done()
+
+	if !m.destination.Init() {
+		m.logger.Warn(fmt.Sprintf("failed to initialize endpoints of mirror %s, connecting on first message", m.name))
+	}
+
+	for {
+		select {
+		case <-m.stop:
+			m.drain()
+			return
+		case mirrored := <-m.messages:
+			m.write(mirrored)
+		}
+	}
+}
+
+// drain writes the queued messages until the queue is empty or the shutdown deadline is exceeded.
+func (m *Mirror) drain() {
+	for {
+		select {
+		case <-m.abort:
+			return
+		case mirrored := <-m.messages:
+			m.write(mirrored)
+		default:
+			return
+		}
+	}
+}
+
+func (m *Mirror) write(mirrored mirroredMessage) {
+	if !m.destination.Refresh() {
+		m.logger.Warn(fmt.Sprintf("failed to refresh endpoints of mirror %s", m.name))
+	}
+
+	if err := m.destination.WriteMessage(mirrored.message, mirrored.key); err != nil {
+		m.logger.Warn(fmt.Sprintf("failed to write message to mirror %s", m.name), zap.Error(err))
+	}
+}
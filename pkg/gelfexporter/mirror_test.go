@@ -0,0 +1,119 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"testing"
+	"time"
+)
+
+func newTestMirror(mirror *MirrorConfig, dialer Dialer) *Mirror {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointInitRetries = 1
+
+	return NewMirror(cfg, mirror, dialer, zap.NewNop())
+}
+
+func TestMirror(t *testing.T) {
+	w := newRecordingWriter()
+
+	m := newTestMirror(&MirrorConfig{Name: "new", Endpoint: "10.0.0.1:12201"}, func(string) (gelf.Writer, error) {
+		return w, nil
+	})
+
+	m.Start()
+
+	for i := 0; i < 10; i++ {
+		m.WriteMessage(&gelf.Message{}, "")
+	}
+
+	require.Eventually(t, func() bool {
+		return len(w.recording.messages()) == 10
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, m.Shutdown(context.Background()))
+}
+
+func TestMirrorSampling(t *testing.T) {
+	w := newRecordingWriter()
+
+	ratio := 0.0
+	m := newTestMirror(&MirrorConfig{Name: "new", Endpoint: "10.0.0.1:12201", SamplingRatio: &ratio}, func(string) (gelf.Writer, error) {
+		return w, nil
+	})
+
+	for i := 0; i < 10; i++ {
+		m.WriteMessage(&gelf.Message{}, "")
+	}
+
+	assert.Empty(t, m.messages)
+	require.NoError(t, m.Shutdown(context.Background()))
+}
+
+func TestMirrorFailureIsolation(t *testing.T) {
+	m := newTestMirror(&MirrorConfig{Name: "old", Endpoint: "10.0.0.1:12201", QueueSize: 2}, func(string) (gelf.Writer, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	// Without the mirror started, messages beyond the queue size are dropped instead of blocking.
+	for i := 0; i < 10; i++ {
+		m.WriteMessage(&gelf.Message{}, "")
+	}
+
+	assert.Len(t, m.messages, 2)
+
+	m.Start()
+
+	require.Eventually(t, func() bool {
+		return len(m.messages) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, m.Shutdown(context.Background()))
+}
+
+func TestMirrorShutdownReturnsOnDeadline(t *testing.T) {
+	release := make(chan struct{})
+	dialing := make(chan struct{})
+
+	m := newTestMirror(&MirrorConfig{Name: "new", Endpoint: "10.0.0.1:12201"}, func(string) (gelf.Writer, error) {
+		close(dialing)
+		<-release
+		return newRecordingWriter(), nil
+	})
+
+	defer close(release)
+
+	m.WriteMessage(&gelf.Message{}, "")
+	m.Start()
+	<-dialing
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, m.Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestMirrorShutdownDrainsQueue(t *testing.T) {
+	w := newRecordingWriter()
+
+	m := newTestMirror(&MirrorConfig{Name: "new", Endpoint: "10.0.0.1:12201"}, func(string) (gelf.Writer, error) {
+		return w, nil
+	})
+
+	for i := 0; i < 10; i++ {
+		m.WriteMessage(&gelf.Message{}, "")
+	}
+
+	m.Start()
+
+	require.NoError(t, m.Shutdown(context.Background()))
+	assert.Len(t, w.recording.messages(), 10)
+}
@@ -0,0 +1,189 @@
+package gelfexporter
+
+import (
+	"context"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"math"
+	"sync/atomic"
+)
+
+// The severity levels of the priority mode, from the highest priority to the lowest.
+const (
+	priorityError = iota
+	priorityWarn
+	priorityInfo
+	priorityDebug
+)
+
+// priorityLevels are the names of the severity levels of the priority mode.
+var priorityLevels = []string{"error", "warn", "info", "debug"}
+
+// Prioritizer orders the log records of batches by severity level and sheds the lower levels
+// as the sending queue fills. The fill of the queue is tracked from the batches queued and dequeued
+// by this exporter, so batches restored by a persistent queue are not counted, and compared to its
+// queue_size, which counts batches too.
+// All methods are no-ops on a nil Prioritizer.
+type Prioritizer struct {
+	capacity   int
+	exporter   string
+	pending    atomic.Int64
+	shed       metric.Int64Counter
+	thresholds []float64
+}
+
+// NewPrioritizer returns the prioritizer of the exporter, or nil if the priority mode is disabled.
+func NewPrioritizer(cfg *Config, set exporter.Settings) (*Prioritizer, error) {
+	if !cfg.Priority.Enabled {
+		return nil, nil
+	}
+
+	shed, err := set.MeterProvider.Meter(scopeName).Int64Counter(
+		"otelcol_exporter_gelf_shed_records",
+		metric.WithDescription("Number of log records shed by the priority mode, by severity level"),
+		metric.WithUnit("{records}"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Prioritizer{
+		exporter: set.ID.String(),
+		shed:     shed,
+		thresholds: []float64{
+			priorityError: math.Inf(1),
+			priorityWarn:  cfg.Priority.ShedThresholds.Warn,
+			priorityInfo:  cfg.Priority.ShedThresholds.Info,
+			priorityDebug: cfg.Priority.ShedThresholds.Debug,
+		},
+	}
+
+	if cfg.QueueConfig.Enabled {
+		p.capacity = cfg.QueueConfig.QueueSize
+	}
+
+	return p, nil
+}
+
+// Prioritize returns the log records of the logs whose levels are not shed at the current fill of the sending queue,
+// from the highest severity level to the lowest, keeping their order within a level.
+func (p *Prioritizer) Prioritize(ld plog.Logs) plog.Logs {
+	if p == nil {
+		return ld
+	}
+
+	levels := logRecordLevels(ld)
+	fill := p.fill()
+	prioritized := plog.NewLogs()
+
+	for level := range priorityLevels {
+		if fill >= p.thresholds[level] {
+			p.report(levels, level)
+			continue
+		}
+
+		copyLogRecords(prioritized, ld, func(i int) bool {
+			return levels[i] == level
+		})
+	}
+
+	return prioritized
+}
+
+// queued counts a batch added to the sending queue.
+func (p *Prioritizer) queued() {
+	if p != nil {
+		p.pending.Add(1)
+	}
+}
+
+// dequeued counts a batch taken from the sending queue, ignoring batches that were not counted when queued.
+func (p *Prioritizer) dequeued() {
+	if p == nil {
+		return
+	}
+
+	for {
+		pending := p.pending.Load()
+
+		if pending <= 0 || p.pending.CompareAndSwap(pending, pending-1) {
+			return
+		}
+	}
+}
+
+// rejected reports the log records of a batch rejected by the full sending queue as shed.
+func (p *Prioritizer) rejected(ld plog.Logs) {
+	if p == nil {
+		return
+	}
+
+	levels := logRecordLevels(ld)
+
+	for level := range priorityLevels {
+		p.report(levels, level)
+	}
+}
+
+// fill returns the share of the sending queue taken by pending batches, or 0 without a sending queue.
+func (p *Prioritizer) fill() float64 {
+	if p.capacity == 0 {
+		return 0
+	}
+
+	return float64(p.pending.Load()) / float64(p.capacity)
+}
+
+func (p *Prioritizer) report(levels []int, level int) {
+	var shed int64
+
+	for _, l := range levels {
+		if l == level {
+			shed++
+		}
+	}
+
+	if shed > 0 {
+		p.shed.Add(context.Background(), shed, metric.WithAttributes(
+			attribute.String("exporter", p.exporter),
+			attribute.String("level", priorityLevels[level]),
+		))
+	}
+}
+
+// logRecordLevels returns the index in priorityLevels of each log record of the logs, in order.
+func logRecordLevels(ld plog.Logs) []int {
+	levels := make([]int, 0, ld.LogRecordCount())
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				levels = append(levels, priorityLevel(sl.LogRecords().At(k)))
+			}
+		}
+	}
+
+	return levels
+}
+
+func priorityLevel(lr plog.LogRecord) int {
+	switch n := severity(lr); {
+	case n == plog.SeverityNumberUnspecified:
+		return priorityInfo
+	case n < plog.SeverityNumberInfo:
+		return priorityDebug
+	case n < plog.SeverityNumberWarn:
+		return priorityInfo
+	case n < plog.SeverityNumberError:
+		return priorityWarn
+	}
+
+	return priorityError
+}
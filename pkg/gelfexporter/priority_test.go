@@ -0,0 +1,146 @@
+package gelfexporter
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/internal/gelftest"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"testing"
+)
+
+// testPriorityResource is a resource holding log records of each severity, with the severity as body.
+var testPriorityResource = testResource{
+	records: []testRecord{
+		{body: "Debug", severity: plog.SeverityNumberDebug},
+		{body: "Error", severity: plog.SeverityNumberError},
+		{body: "Info", severity: plog.SeverityNumberInfo},
+		{body: "Warn", severity: plog.SeverityNumberWarn},
+		{body: "Unspecified", severity: plog.SeverityNumberUnspecified},
+		{body: "Fatal", severity: plog.SeverityNumberFatal},
+		{body: "Trace", severity: plog.SeverityNumberTrace},
+	},
+}
+
+// shedRecords returns the number of shed log records reported by level.
+func shedRecords(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	var rm metricdata.ResourceMetrics
+
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	shed := make(map[string]int64)
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "otelcol_exporter_gelf_shed_records" {
+				continue
+			}
+
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				level, _ := point.Attributes.Value("level")
+				shed[level.AsString()] += point.Value
+			}
+		}
+	}
+
+	return shed
+}
+
+func TestPrioritizer(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending int64
+		want    []string
+		shed    map[string]int64
+	}{
+		{
+			name:    "Empty",
+			pending: 0,
+			want:    []string{"Error", "Fatal", "Warn", "Info", "Unspecified", "Debug", "Trace"},
+			shed:    map[string]int64{},
+		},
+		{
+			name:    "ShedDebug",
+			pending: 5,
+			want:    []string{"Error", "Fatal", "Warn", "Info", "Unspecified"},
+			shed:    map[string]int64{"debug": 2},
+		},
+		{
+			name:    "ShedInfo",
+			pending: 8,
+			want:    []string{"Error", "Fatal", "Warn"},
+			shed:    map[string]int64{"debug": 2, "info": 2},
+		},
+		{
+			name:    "Full",
+			pending: 10,
+			want:    []string{"Error", "Fatal"},
+			shed:    map[string]int64{"debug": 2, "info": 2, "warn": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := sdkmetric.NewManualReader()
+			set := exportertest.NewNopSettings(component.MustNewType(UdpExporterType))
+			set.MeterProvider = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.Priority.Enabled = true
+			cfg.QueueConfig.QueueSize = 10
+
+			p, err := NewPrioritizer(cfg, set)
+			require.NoError(t, err)
+
+			p.pending.Store(tt.pending)
+
+			assert.Equal(t, tt.want, gelftest.LogBodies(p.Prioritize(newTestLogs(testPriorityResource))))
+			assert.Equal(t, tt.shed, shedRecords(t, reader))
+		})
+	}
+}
+
+func TestPrioritizerTracksSendingQueue(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Priority.Enabled = true
+	cfg.QueueConfig.QueueSize = 2
+
+	p, err := NewPrioritizer(cfg, exportertest.NewNopSettings(component.MustNewType(UdpExporterType)))
+	require.NoError(t, err)
+
+	p.queued()
+	assert.Equal(t, 0.5, p.fill())
+
+	p.dequeued()
+	p.dequeued()
+	assert.Equal(t, 0.0, p.fill())
+
+	var disabled *Prioritizer
+
+	assert.Equal(t, 1, disabled.Prioritize(newTestLogs(testPriorityResource)).ResourceLogs().Len())
+}
+
+func TestNewLogsPriority(t *testing.T) {
+	var pushed []string
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Priority.Enabled = true
+	cfg.QueueConfig.Enabled = false
+
+	e, err := NewLogs(context.Background(), exportertest.NewNopSettings(component.MustNewType(UdpExporterType)), cfg, cfg, nil,
+		func(_ context.Context, ld plog.Logs) error {
+			pushed = gelftest.LogBodies(ld)
+			return nil
+		})
+	require.NoError(t, err)
+	require.NoError(t, e.Start(context.Background(), componenttest.NewNopHost()))
+
+	assert.NoError(t, e.ConsumeLogs(context.Background(), newTestLogs(testPriorityResource)))
+	assert.Equal(t, []string{"Error", "Fatal", "Warn", "Info", "Unspecified", "Debug", "Trace"}, pushed)
+	assert.NoError(t, e.Shutdown(context.Background()))
+}
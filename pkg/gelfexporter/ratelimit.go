@@ -0,0 +1,318 @@
+package gelfexporter
+
+import (
+	"bytes"
+	"context"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimitSweepKeys is the number of keys tracked by the rate limiter before idle keys are forgotten.
+const rateLimitSweepKeys = 1024
+
+var severityNumbers = map[string]plog.SeverityNumber{
+	"TRACE": plog.SeverityNumberTrace,
+	"DEBUG": plog.SeverityNumberDebug,
+	"INFO":  plog.SeverityNumberInfo,
+	"WARN":  plog.SeverityNumberWarn,
+	"ERROR": plog.SeverityNumberError,
+	"FATAL": plog.SeverityNumberFatal,
+}
+
+// RateLimiter limits the rate of messages and bytes written by the exporter with token buckets,
+// in total and for each value of the key attribute. Each bucket holds one second worth of tokens,
+// and a message larger than that is allowed once its bucket is full.
+// All methods are no-ops on a nil RateLimiter, which never limits messages.
+type RateLimiter struct {
+	config      *RateLimitConfig
+	dropped     metric.Int64Counter
+	exporter    string
+	keys        map[string]*rateLimits
+	limits      *rateLimits
+	lock        sync.Mutex
+	minSeverity plog.SeverityNumber
+	sweepAt     int
+}
+
+// NewRateLimiter returns the rate limiter of the exporter, or nil if rate limiting is disabled.
+func NewRateLimiter(cfg *RateLimitConfig, set exporter.Settings) (*RateLimiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	dropped, err := set.MeterProvider.Meter(scopeName).Int64Counter(
+		"otelcol_exporter_gelf_rate_limited_records",
+		metric.WithDescription("Number of log records dropped by the rate limiter"),
+		metric.WithUnit("{records}"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	return &RateLimiter{
+		config:      cfg,
+		dropped:     dropped,
+		exporter:    set.ID.String(),
+		keys:        make(map[string]*rateLimits),
+		limits:      newRateLimits(cfg.MessagesPerSecond, cfg.BytesPerSecond, now),
+		minSeverity: severityNumbers[strings.ToUpper(cfg.MinSeverity)],
+		sweepAt:     rateLimitSweepKeys,
+	}, nil
+}
+
+// Logs returns the log records of the logs for admitting the messages converted from them, or nil without a limiter.
+func (l *RateLimiter) Logs(ld plog.Logs) *RateLimitedLogs {
+	if l == nil {
+		return nil
+	}
+
+	r := &RateLimitedLogs{limiter: l}
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		key := l.key(rl.Resource())
+
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				r.records = append(r.records, rateLimitedRecord{key: key, severity: severity(sl.LogRecords().At(k))})
+			}
+		}
+	}
+
+	return r
+}
+
+func (l *RateLimiter) key(resource pcommon.Resource) string {
+	if l.config.Key.Attribute == "" {
+		return ""
+	}
+
+	return attributeValue(resource.Attributes(), l.config.Key.Attribute)
+}
+
+// admit reports whether the message may be written, waiting for the limits with the "block" action.
+func (l *RateLimiter) admit(ctx context.Context, record rateLimitedRecord, m *gelf.Message) (bool, error) {
+	var size float64
+
+	if l.config.BytesPerSecond > 0 || l.config.Key.BytesPerSecond > 0 {
+		size = messageSize(m)
+	}
+
+	l.lock.Lock()
+
+	now := time.Now()
+	keyLimits := l.keyLimits(record.key, now)
+	wait := max(l.limits.delay(size, now), keyLimits.delay(size, now))
+
+	switch {
+	case wait == 0:
+		l.limits.take(size)
+		keyLimits.take(size)
+		l.lock.Unlock()
+
+		return true, nil
+	case l.config.Action == RateLimitActionDowngrade && record.severity >= l.minSeverity:
+		l.lock.Unlock()
+
+		return true, nil
+	case l.config.Action != RateLimitActionBlock:
+		l.lock.Unlock()
+		l.drop(record.key)
+
+		return false, nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		l.lock.Unlock()
+
+		return false, &RateLimitError{Err: context.DeadlineExceeded, Key: record.key}
+	}
+
+	// Taking the tokens ahead of time queues the following messages behind this one.
+	l.limits.take(size)
+	keyLimits.take(size)
+	l.lock.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true, nil
+	case <-ctx.Done():
+		return false, &RateLimitError{Err: ctx.Err(), Key: record.key}
+	}
+}
+
+// keyLimits returns the limits of the key, forgetting the keys whose buckets are full once there are too many,
+// as a full bucket limits the same way as a new one.
+func (l *RateLimiter) keyLimits(key string, now time.Time) *rateLimits {
+	if l.config.Key.Attribute == "" {
+		return nil
+	}
+
+	if limits, ok := l.keys[key]; ok {
+		return limits
+	}
+
+	if len(l.keys) >= l.sweepAt {
+		for k, limits := range l.keys {
+			if limits.full(now) {
+				delete(l.keys, k)
+			}
+		}
+
+		l.sweepAt = max(rateLimitSweepKeys, 2*len(l.keys))
+	}
+
+	l.keys[key] = newRateLimits(l.config.Key.MessagesPerSecond, l.config.Key.BytesPerSecond, now)
+
+	return l.keys[key]
+}
+
+func (l *RateLimiter) drop(key string) {
+	l.dropped.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("action", l.config.Action),
+		attribute.String("exporter", l.exporter),
+		attribute.String("key", key),
+	))
+}
+
+// RateLimitedLogs admits the messages converted from the log records of logs, one per log record, in order.
+type RateLimitedLogs struct {
+	limiter *RateLimiter
+	records []rateLimitedRecord
+}
+
+type rateLimitedRecord struct {
+	key      string
+	severity plog.SeverityNumber
+}
+
+// Admit reports whether the message converted from the i-th log record may be written.
+// It returns a RateLimitError if the "block" action can't wait for the limits before the context is done.
+func (r *RateLimitedLogs) Admit(ctx context.Context, i int, m *gelf.Message) (bool, error) {
+	if r == nil || i >= len(r.records) {
+		return true, nil
+	}
+
+	return r.limiter.admit(ctx, r.records[i], m)
+}
+
+// rateLimits are the token buckets of messages and bytes, each nil if it is not limited.
+type rateLimits struct {
+	bytes    *tokenBucket
+	messages *tokenBucket
+}
+
+func newRateLimits(messagesPerSecond float64, bytesPerSecond float64, now time.Time) *rateLimits {
+	return &rateLimits{
+		bytes:    newTokenBucket(bytesPerSecond, now),
+		messages: newTokenBucket(messagesPerSecond, now),
+	}
+}
+
+// delay returns the time until a message of the size is allowed by both buckets.
+func (r *rateLimits) delay(size float64, now time.Time) time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	return max(r.bytes.delay(size, now), r.messages.delay(1, now))
+}
+
+func (r *rateLimits) take(size float64) {
+	if r != nil {
+		r.bytes.take(size)
+		r.messages.take(1)
+	}
+}
+
+func (r *rateLimits) full(now time.Time) bool {
+	return r.bytes.full(now) && r.messages.full(now)
+}
+
+type tokenBucket struct {
+	capacity float64
+	rate     float64
+	tokens   float64
+	updated  time.Time
+}
+
+// newTokenBucket returns a full bucket holding one second worth of tokens, or nil if the rate is not limited.
+func newTokenBucket(rate float64, now time.Time) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+
+	return &tokenBucket{capacity: rate, rate: rate, tokens: rate, updated: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = min(b.capacity, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// delay returns the time until n tokens are available, capped at a full bucket.
+func (b *tokenBucket) delay(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.refill(now)
+
+	if n = min(n, b.capacity); b.tokens >= n {
+		return 0
+	}
+
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// take removes n tokens, capped at a full bucket, leaving the bucket in debt if it doesn't hold them yet.
+func (b *tokenBucket) take(n float64) {
+	if b != nil {
+		b.tokens -= min(n, b.capacity)
+	}
+}
+
+func (b *tokenBucket) full(now time.Time) bool {
+	if b == nil {
+		return true
+	}
+
+	b.refill(now)
+
+	return b.tokens >= b.capacity
+}
+
+// messageSize returns the size of the message serialized as JSON, before any compression.
+func messageSize(m *gelf.Message) float64 {
+	var buf bytes.Buffer
+
+	if err := m.MarshalJSONBuf(&buf); err != nil {
+		return 0
+	}
+
+	return float64(buf.Len())
+}
+
+// severity returns the severity number of the log record, falling back to its severity text.
+func severity(lr plog.LogRecord) plog.SeverityNumber {
+	if lr.SeverityNumber() != plog.SeverityNumberUnspecified {
+		return lr.SeverityNumber()
+	}
+
+	return severityNumbers[strings.ToUpper(lr.SeverityText())]
+}
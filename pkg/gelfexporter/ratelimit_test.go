@@ -0,0 +1,144 @@
+package gelfexporter
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(t *testing.T, cfg RateLimitConfig) *RateLimiter {
+	cfg.Enabled = true
+
+	if cfg.MinSeverity == "" {
+		cfg.MinSeverity = DefaultRateLimitMinSeverity
+	}
+
+	require.NoError(t, cfg.Validate())
+
+	l, err := NewRateLimiter(&cfg, exportertest.NewNopSettings(component.MustNewType(UdpExporterType)))
+	require.NoError(t, err)
+
+	return l
+}
+
+// testInfoRecord is the log record of each service of the logs of the rate limiter tests.
+var testInfoRecord = testRecord{severity: plog.SeverityNumberInfo}
+
+// admitted returns the indexes of the log records whose messages are admitted.
+func admitted(t *testing.T, l *RateLimiter, ld plog.Logs) []int {
+	var indexes []int
+
+	limited := l.Logs(ld)
+
+	for i := 0; i < ld.LogRecordCount(); i++ {
+		ok, err := limited.Admit(context.Background(), i, &gelf.Message{Short: "message"})
+		require.NoError(t, err)
+
+		if ok {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes
+}
+
+func TestRateLimiterDrop(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      RateLimitConfig
+		services []string
+		want     []int
+	}{
+		{
+			name:     "Messages",
+			cfg:      RateLimitConfig{Action: RateLimitActionDrop, MessagesPerSecond: 2},
+			services: []string{"a", "b", "c"},
+			want:     []int{0, 1},
+		},
+		{
+			name: "MessagesPerKey",
+			cfg: RateLimitConfig{
+				Action: RateLimitActionDrop,
+				Key:    RateLimitKeyConfig{Attribute: "service.name", MessagesPerSecond: 1},
+			},
+			services: []string{"a", "a", "b"},
+			want:     []int{0, 2},
+		},
+		{
+			name: "GlobalAndPerKey",
+			cfg: RateLimitConfig{
+				Action:            RateLimitActionDrop,
+				Key:               RateLimitKeyConfig{Attribute: "service.name", MessagesPerSecond: 1},
+				MessagesPerSecond: 2,
+			},
+			services: []string{"a", "a", "b", "c"},
+			want:     []int{0, 2},
+		},
+		{
+			name:     "Bytes",
+			cfg:      RateLimitConfig{Action: RateLimitActionDrop, BytesPerSecond: 100},
+			services: []string{"a", "b", "c"},
+			want:     []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestRateLimiter(t, tt.cfg)
+			assert.Equal(t, tt.want, admitted(t, l, newTestLogs(resourcesOf("service.name", tt.services, testInfoRecord)...)))
+		})
+	}
+}
+
+func TestRateLimiterDowngrade(t *testing.T) {
+	l := newTestRateLimiter(t, RateLimitConfig{Action: RateLimitActionDowngrade, MessagesPerSecond: 1})
+	ld := newTestLogs(resourcesOf("service.name", []string{"a", "a", "a"}, testInfoRecord)...)
+	ld.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0).SetSeverityNumber(plog.SeverityNumberError)
+	ld.ResourceLogs().At(2).ScopeLogs().At(0).LogRecords().At(0).SetSeverityNumber(plog.SeverityNumberUnspecified)
+	ld.ResourceLogs().At(2).ScopeLogs().At(0).LogRecords().At(0).SetSeverityText("warn")
+
+	assert.Equal(t, []int{0, 1, 2}, admitted(t, l, ld))
+	assert.Equal(t, []int(nil), admitted(t, l, newTestLogs(resourcesOf("service.name", []string{"a"}, testInfoRecord)...)))
+}
+
+func TestRateLimiterBlock(t *testing.T) {
+	l := newTestRateLimiter(t, RateLimitConfig{Action: RateLimitActionBlock, MessagesPerSecond: 20})
+	limited := l.Logs(newTestLogs(resourcesOf("service.name", make([]string, 22), testInfoRecord)...))
+	start := time.Now()
+
+	for i := 0; i < 22; i++ {
+		ok, err := limited.Admit(context.Background(), i, &gelf.Message{})
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	// The bucket holds 20 messages, and the remaining 2 wait for it to refill.
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	ok, err := limited.Admit(ctx, 0, &gelf.Message{})
+
+	var rateLimitErr *RateLimitError
+
+	assert.False(t, ok)
+	assert.ErrorAs(t, err, &rateLimitErr)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	l, err := NewRateLimiter(&RateLimitConfig{}, exportertest.NewNopSettings(component.MustNewType(UdpExporterType)))
+	require.NoError(t, err)
+	assert.Nil(t, l)
+
+	ok, err := l.Logs(newTestLogs(resourcesOf("service.name", []string{"a"}, testInfoRecord)...)).Admit(context.Background(), 0, &gelf.Message{})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
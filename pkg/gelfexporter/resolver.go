@@ -0,0 +1,345 @@
+package gelfexporter
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/miekg/dns"
+	"math"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsResolverConfigFile is the system resolver configuration used for TTL-aware lookups.
+	dnsResolverConfigFile = "/etc/resolv.conf"
+
+	// literalTTL is the TTL reported for IP literals, which never expire.
+	literalTTL = time.Duration(math.MaxUint32) * time.Second
+)
+
+var (
+	systemDNSResolver     resolver
+	systemDNSResolverOnce sync.Once
+)
+
+// resolver looks up IP addresses and SRV records together with the TTL of the answer.
+// A zero TTL means that the TTL is unknown.
+type resolver interface {
+	LookupIP(host string) ([]net.IP, time.Duration, error)
+	LookupSRV(name string) ([]*net.SRV, time.Duration, error)
+}
+
+// systemResolver uses the Go resolver, which does not expose record TTLs.
+type systemResolver struct{}
+
+func (systemResolver) LookupIP(host string) ([]net.IP, time.Duration, error) {
+	ips, err := lookupIP(host)
+	return ips, 0, err
+}
+
+func (systemResolver) LookupSRV(name string) ([]*net.SRV, time.Duration, error) {
+	_, records, err := lookupSRV("", "", name)
+	return records, 0, err
+}
+
+// Resolver resolves endpoints according to the exporter configuration.
+type Resolver struct {
+	hosts    map[string][]net.IP
+	ipFamily string
+	resolver resolver
+}
+
+// NewResolver creates a resolver using the configured nameservers and hosts overrides.
+// Without nameservers the system resolver is used, querying the system nameservers directly
+// when the endpoint refresh strategy relies on record TTLs.
+func NewResolver(cfg *Config) *Resolver {
+	r := &Resolver{
+		hosts:    make(map[string][]net.IP, len(cfg.Resolver.Hosts)),
+		ipFamily: cfg.IPFamily,
+	}
+
+	for host, addresses := range cfg.Resolver.Hosts {
+		name := dns.CanonicalName(host)
+
+		for _, address := range addresses {
+			if ip := net.ParseIP(address); ip != nil {
+				r.hosts[name] = append(r.hosts[name], ip)
+			}
+		}
+	}
+
+	switch {
+	case len(cfg.Resolver.Nameservers) > 0:
+		r.resolver = newConfiguredDNSResolver(&cfg.Resolver)
+	case cfg.EndpointRefreshStrategy == EndpointRefreshStrategyDNSTTL:
+		r.resolver = getSystemDNSResolver()
+	default:
+		r.resolver = systemResolver{}
+	}
+
+	return r
+}
+
+// Resolve resolves the endpoint into addresses in the order connection attempts should be made,
+// and returns the lowest TTL of the involved DNS records. A zero TTL means that it is unknown.
+func (r *Resolver) Resolve(endpoint string) ([]string, time.Duration, error) {
+	return resolveEndpoints(r, endpoint, r.ipFamily)
+}
+
+// ResolveEndpoint resolves the endpoint into the first address connection attempts should be made to.
+func (r *Resolver) ResolveEndpoint(endpoint string) (string, error) {
+	endpoints, _, err := r.Resolve(endpoint)
+
+	if err != nil {
+		return "", err
+	}
+
+	return endpoints[0], nil
+}
+
+func (r *Resolver) LookupIP(host string) ([]net.IP, time.Duration, error) {
+	if ips, ok := r.hosts[dns.CanonicalName(host)]; ok {
+		return ips, literalTTL, nil
+	}
+
+	return r.resolver.LookupIP(host)
+}
+
+func (r *Resolver) LookupSRV(name string) ([]*net.SRV, time.Duration, error) {
+	return r.resolver.LookupSRV(name)
+}
+
+// dnsResolver queries nameservers directly, so that record TTLs are available.
+type dnsResolver struct {
+	client   *dns.Client
+	config   *dns.ClientConfig
+	fallback bool
+	servers  []string
+}
+
+func newDNSResolver(config *dns.ClientConfig, timeout time.Duration) *dnsResolver {
+	servers := make([]string, 0, len(config.Servers))
+
+	for _, server := range config.Servers {
+		servers = append(servers, net.JoinHostPort(server, config.Port))
+	}
+
+	return &dnsResolver{
+		client:   &dns.Client{Timeout: timeout},
+		config:   config,
+		fallback: true,
+		servers:  servers,
+	}
+}
+
+func newConfiguredDNSResolver(cfg *ResolverConfig) *dnsResolver {
+	var port = "53"
+	var client = &dns.Client{Net: cfg.Protocol, Timeout: cfg.Timeout}
+
+	if cfg.Protocol == ResolverProtocolDoT {
+		port = "853"
+		client.Net = "tcp-tls"
+		client.TLSConfig = &tls.Config{ServerName: cfg.TLSServerName}
+	}
+
+	servers := make([]string, 0, len(cfg.Nameservers))
+
+	for _, server := range cfg.Nameservers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, port)
+		}
+
+		servers = append(servers, server)
+	}
+
+	return &dnsResolver{
+		client:  client,
+		config:  &dns.ClientConfig{Ndots: 1},
+		servers: servers,
+	}
+}
+
+// getSystemDNSResolver returns a resolver querying the system nameservers,
+// falling back to the Go resolver if they can't be determined.
+func getSystemDNSResolver() resolver {
+	systemDNSResolverOnce.Do(func() {
+		config, err := dns.ClientConfigFromFile(dnsResolverConfigFile)
+
+		if err != nil || len(config.Servers) == 0 {
+			systemDNSResolver = systemResolver{}
+			return
+		}
+
+		systemDNSResolver = newDNSResolver(config, time.Duration(config.Timeout)*time.Second)
+	})
+
+	return systemDNSResolver
+}
+
+func (r *dnsResolver) LookupIP(host string) ([]net.IP, time.Duration, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, literalTTL, nil
+	}
+
+	var errs []error
+
+	for _, name := range r.config.NameList(host) {
+		var ips []net.IP
+		var ttl time.Duration
+
+		for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+			answer, err := r.exchange(name, qtype)
+
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			for _, rr := range answer {
+				switch record := rr.(type) {
+				case *dns.A:
+					ips = append(ips, record.A)
+				case *dns.AAAA:
+					ips = append(ips, record.AAAA)
+				default:
+					continue
+				}
+
+				ttl = minTTL(ttl, time.Duration(rr.Header().Ttl)*time.Second)
+			}
+		}
+
+		if len(ips) > 0 {
+			return ips, ttl, nil
+		}
+	}
+
+	// Names such as "localhost" are usually only known to the hosts file.
+	if r.fallback {
+		if ips, err := lookupIP(host); err == nil {
+			return ips, 0, nil
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, 0, errors.Join(errs...)
+	}
+
+	return nil, 0, fmt.Errorf("no address found for %s", host)
+}
+
+func (r *dnsResolver) LookupSRV(name string) ([]*net.SRV, time.Duration, error) {
+	answer, err := r.exchange(dns.Fqdn(name), dns.TypeSRV)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var records []*net.SRV
+	var ttl time.Duration
+
+	for _, rr := range answer {
+		if record, ok := rr.(*dns.SRV); ok {
+			records = append(records, &net.SRV{
+				Target:   record.Target,
+				Port:     record.Port,
+				Priority: record.Priority,
+				Weight:   record.Weight,
+			})
+
+			ttl = minTTL(ttl, time.Duration(rr.Header().Ttl)*time.Second)
+		}
+	}
+
+	sortSRV(records)
+
+	return records, ttl, nil
+}
+
+// exchange sends the query to the nameservers in order and returns the answer section of the first response.
+func (r *dnsResolver) exchange(name string, qtype uint16) ([]dns.RR, error) {
+	var errs []error
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(name, qtype)
+
+	for _, server := range r.servers {
+		response, _, err := r.client.Exchange(msg, server)
+
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if response.Rcode != dns.RcodeSuccess {
+			return nil, fmt.Errorf("lookup %s %s: %s", dns.TypeToString[qtype], name, dns.RcodeToString[response.Rcode])
+		}
+
+		return response.Answer, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("no nameserver configured")
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// minTTL returns the lower of two TTLs, where zero means that the TTL was not set yet.
+func minTTL(a time.Duration, b time.Duration) time.Duration {
+	if a == 0 || b < a {
+		return b
+	}
+
+	return a
+}
+
+// sortSRV orders records by priority and shuffles records of the same priority by weight (RFC 2782).
+func sortSRV(records []*net.SRV) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Priority < records[j].Priority
+	})
+
+	for start := 0; start < len(records); {
+		end := start + 1
+
+		for end < len(records) && records[end].Priority == records[start].Priority {
+			end++
+		}
+
+		shuffleSRVByWeight(records[start:end])
+		start = end
+	}
+}
+
+func shuffleSRVByWeight(records []*net.SRV) {
+	var sum int
+
+	for _, record := range records {
+		sum += int(record.Weight)
+	}
+
+	for sum > 0 && len(records) > 1 {
+		var s int
+		n := rand.IntN(sum)
+
+		for i := range records {
+			s += int(records[i].Weight)
+
+			if s > n {
+				if i > 0 {
+					records[0], records[i] = records[i], records[0]
+				}
+
+				break
+			}
+		}
+
+		sum -= int(records[0].Weight)
+		records = records[1:]
+	}
+}
@@ -0,0 +1,152 @@
+package gelfexporter
+
+import (
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+	"time"
+)
+
+// startTestDNSServer starts an in-process nameserver answering from the records.
+func startTestDNSServer(t *testing.T, records map[uint16][]string) *dns.ClientConfig {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+
+		for _, record := range records[r.Question[0].Qtype] {
+			rr, err := dns.NewRR(record)
+			require.NoError(t, err)
+
+			if rr.Header().Name == r.Question[0].Name {
+				m.Answer = append(m.Answer, rr)
+			}
+		}
+
+		_ = w.WriteMsg(m)
+	})
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+
+	<-started
+
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	host, port, err := net.SplitHostPort(conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	return &dns.ClientConfig{Servers: []string{host}, Port: port, Ndots: 1, Timeout: 1, Attempts: 1}
+}
+
+func TestDNSResolverLookupIP(t *testing.T) {
+	config := startTestDNSServer(t, map[uint16][]string{
+		dns.TypeA: {
+			"graylog.example.com. 30 IN A 10.0.0.1",
+			"graylog.example.com. 20 IN A 10.0.0.2",
+		},
+		dns.TypeAAAA: {
+			"graylog.example.com. 60 IN AAAA fd00::1",
+		},
+	})
+
+	r := newDNSResolver(config, time.Second)
+
+	ips, ttl, err := r.LookupIP("graylog.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, ttl)
+	assert.Len(t, ips, 3)
+
+	endpoints, ttl, err := resolveEndpoints(r, "graylog.example.com:12201", IPFamilyIPv4)
+	require.NoError(t, err)
+	assert.Equal(t, 20*time.Second, ttl)
+	assert.Equal(t, []string{"10.0.0.1:12201", "10.0.0.2:12201"}, endpoints)
+
+	ips, ttl, err = r.LookupIP("10.1.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, literalTTL, ttl)
+	assert.Equal(t, []net.IP{net.ParseIP("10.1.1.1")}, ips)
+}
+
+func TestDNSResolverLookupSRV(t *testing.T) {
+	config := startTestDNSServer(t, map[uint16][]string{
+		dns.TypeSRV: {
+			"_gelf._tcp.example.com. 45 IN SRV 20 0 12202 graylog2.example.com.",
+			"_gelf._tcp.example.com. 90 IN SRV 10 0 12201 graylog1.example.com.",
+		},
+		dns.TypeA: {
+			"graylog1.example.com. 30 IN A 10.0.0.1",
+			"graylog2.example.com. 300 IN A 10.0.0.2",
+		},
+	})
+
+	r := newDNSResolver(config, time.Second)
+
+	endpoints, ttl, err := resolveEndpoints(r, "srv://_gelf._tcp.example.com", IPFamilyAny)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+	assert.Equal(t, []string{"10.0.0.1:12201", "10.0.0.2:12202"}, endpoints)
+}
+
+func TestSortSRV(t *testing.T) {
+	records := []*net.SRV{
+		{Target: "c", Priority: 30, Weight: 10},
+		{Target: "a", Priority: 10, Weight: 0},
+		{Target: "b", Priority: 20, Weight: 10},
+		{Target: "d", Priority: 10, Weight: 100},
+	}
+
+	sortSRV(records)
+
+	assert.Equal(t, "d", records[0].Target)
+	assert.Equal(t, "a", records[1].Target)
+	assert.Equal(t, "b", records[2].Target)
+	assert.Equal(t, "c", records[3].Target)
+}
+
+func TestResolver(t *testing.T) {
+	config := startTestDNSServer(t, map[uint16][]string{
+		dns.TypeA: {
+			"graylog.internal. 30 IN A 10.0.0.1",
+		},
+	})
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Resolver.Nameservers = []string{net.JoinHostPort(config.Servers[0], config.Port)}
+	cfg.Resolver.Timeout = time.Second
+	cfg.Resolver.Hosts = map[string][]string{"Graylog.Static": {"10.0.0.9"}}
+
+	r := NewResolver(cfg)
+
+	endpoints, ttl, err := r.Resolve("graylog.internal:12201")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, ttl)
+	assert.Equal(t, []string{"10.0.0.1:12201"}, endpoints)
+
+	endpoints, ttl, err = r.Resolve("graylog.static:12201")
+	require.NoError(t, err)
+	assert.Equal(t, literalTTL, ttl)
+	assert.Equal(t, []string{"10.0.0.9:12201"}, endpoints)
+
+	_, _, err = r.Resolve("unknown.internal:12201")
+	require.Error(t, err)
+
+	endpoint, err := r.ResolveEndpoint("graylog.static:12201")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.9:12201", endpoint)
+
+	_, err = r.ResolveEndpoint("unknown.internal:12201")
+	require.Error(t, err)
+}
@@ -0,0 +1,461 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottlresource"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultRouteName is the name of the route formed by the endpoints of the exporter.
+const DefaultRouteName = "default"
+
+const scopeName = "github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+
+// DialerFactory returns the dialer for endpoints overriding the TLS settings of the exporter,
+// or for the endpoints of the exporter if endpointTLS is nil.
+type DialerFactory func(endpointTLS *RouteEndpointTLS) Dialer
+
+// Router sends logs to the destination of the first route they match,
+// or to the default destination if they match no route, and copies them to the mirrors.
+type Router struct {
+	breaker        *CircuitBreaker
+	connectOnStart string
+	connectOnce    sync.Once
+	connected      atomic.Bool
+	connecting     chan struct{}
+	destination    *Destination
+	logger         *zap.Logger
+	mirrors        []*Mirror
+	routes         []*route
+}
+
+type route struct {
+	breaker     *CircuitBreaker
+	destination *Destination
+	match       *routeMatcher
+	name        string
+}
+
+// RoutedLogs are logs sent to the same route.
+type RoutedLogs struct {
+	// Breaker is the circuit breaker of the route, which is nil if it is disabled.
+	Breaker     *CircuitBreaker
+	Destination *Destination
+	Logs        plog.Logs
+	Route       string
+}
+
+// NewRouter creates the destinations of the routes and mirrors, and their circuit breakers if enabled.
+// If fallback is not nil, the destination of each route switches to the fallback transport after all its endpoints failed.
+func NewRouter(cfg *Config, dialer DialerFactory, fallback *Fallback, set exporter.Settings) (*Router, error) {
+	transitions, err := set.MeterProvider.Meter(scopeName).Int64Counter(
+		"otelcol_exporter_gelf_circuit_breaker_transitions",
+		metric.WithDescription("Number of state changes of the circuit breakers of the routes"),
+		metric.WithUnit("{transitions}"),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newBreaker := func(route string, logger *zap.Logger) *CircuitBreaker {
+		if !cfg.CircuitBreaker.Enabled {
+			return nil
+		}
+
+		return NewCircuitBreaker(&cfg.CircuitBreaker, route, func(state string) {
+			transitions.Add(context.Background(), 1, metric.WithAttributes(
+				attribute.String("exporter", set.ID.String()),
+				attribute.String("route", route),
+				attribute.String("state", state),
+			))
+		}, logger)
+	}
+
+	r := &Router{
+		breaker:        newBreaker(DefaultRouteName, set.Logger),
+		connectOnStart: cfg.ConnectOnStart,
+		connecting:     make(chan struct{}),
+		destination:    NewDestination(cfg, dialer(nil), set.Logger),
+		logger:         set.Logger,
+		mirrors:        make([]*Mirror, 0, len(cfg.Mirrors)),
+		routes:         make([]*route, 0, len(cfg.Routes)),
+	}
+
+	for i := range cfg.Mirrors {
+		mirrorCfg := &cfg.Mirrors[i]
+		r.mirrors = append(r.mirrors, NewMirror(cfg, mirrorCfg, dialer(&mirrorCfg.EndpointTLS), set.Logger))
+	}
+
+	if fallback != nil {
+		r.destination.addFallback(cfg, fallback)
+	}
+
+	for i := range cfg.Routes {
+		routeCfg := &cfg.Routes[i]
+		match, err := newRouteMatcher(&routeCfg.Match, set.TelemetrySettings)
+
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %w", routeCfg.Name, err)
+		}
+
+		routeEndpoints := cfg.withEndpoints(routeCfg.Endpoint, routeCfg.Endpoints, routeCfg.FailoverEndpoints)
+		logger := set.Logger.With(zap.String("route", routeCfg.Name))
+		destination := NewDestination(routeEndpoints, dialer(&routeCfg.EndpointTLS), logger)
+
+		if fallback != nil {
+			destination.addFallback(routeEndpoints, fallback)
+		}
+
+		r.routes = append(r.routes, &route{
+			breaker:     newBreaker(routeCfg.Name, logger),
+			destination: destination,
+			match:       match,
+			name:        routeCfg.Name,
+		})
+	}
+
+	return r, nil
+}
+
+// Start starts the mirrors and initializes the destinations of all routes according to ConnectOnStart.
+// It reports false only if the initialization is blocking and a route is unavailable.
+func (r *Router) Start() bool {
+	for _, mirror := range r.mirrors {
+		mirror.Start()
+	}
+
+	switch r.connectOnStart {
+	case ConnectOnStartBackground:
+		r.connect()
+	case ConnectOnStartLazy:
+		r.logger.Info("endpoints will be initialized once the first logs are exported")
+	default:
+		if !r.Init() {
+			return false
+		}
+
+		r.connected.Store(true)
+	}
+
+	return true
+}
+
+// Ready reports whether the destinations were initialized.
+// With the lazy ConnectOnStart, the first call starts initializing them in the background.
+func (r *Router) Ready() bool {
+	if r.connected.Load() {
+		return true
+	}
+
+	if r.connectOnStart == ConnectOnStartLazy {
+		r.connect()
+	}
+
+	return false
+}
+
+// connect initializes the destinations of all routes in the background, once.
+// Once it is done, the routes are ready even if some endpoints failed,
+// as writing a message connects its endpoint if it isn't connected yet.
+func (r *Router) connect() {
+	r.connectOnce.Do(func() {
+		r.logger.Info("initializing endpoints in the background")
+
+		go func() {
+			defer close(r.connecting)
+
+			if !r.Init() {
+				r.logger.Warn("failed to initialize endpoints in the background, connecting on next write")
+			}
+
+			r.connected.Store(true)
+		}()
+	})
+}
+
+// Init initializes the destinations of all routes.
+// It reports whether all routes are available.
+func (r *Router) Init() bool {
+	var wg sync.WaitGroup
+
+	destinations := r.destinations()
+	initialized := make([]bool, len(destinations))
+
+	for i, destination := range destinations {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			initialized[i] = destination.Init()
+		}()
+	}
+
+	wg.Wait()
+
+	for i, ok := range initialized {
+		if ok {
+			continue
+		}
+
+		if i == 0 {
+			r.logger.Error(fmt.Sprintf("failed to initialize endpoints of route %s", DefaultRouteName))
+		} else {
+			r.logger.Error(fmt.Sprintf("failed to initialize endpoints of route %s", r.routes[i-1].name))
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// Group splits the logs by the route they match, keeping their resource and scope.
+// Groups are returned in order of first appearance.
+func (r *Router) Group(ctx context.Context, ld plog.Logs) []RoutedLogs {
+	if len(r.routes) == 0 {
+		return []RoutedLogs{{Breaker: r.breaker, Destination: r.destination, Logs: ld, Route: DefaultRouteName}}
+	}
+
+	g := newLogGrouper()
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+
+		if route, ok := r.matchResource(ctx, rl); ok {
+			g.addResource(route, rl)
+			continue
+		}
+
+		g.addRecords(rl, func(sl plog.ScopeLogs, lr plog.LogRecord) string {
+			return r.matchRecord(ctx, rl, sl, lr)
+		})
+	}
+
+	routed := make([]RoutedLogs, 0, len(g.groups))
+
+	for _, group := range g.groups {
+		routed = append(routed, RoutedLogs{Breaker: r.breaker, Destination: r.destination, Logs: group.Logs, Route: DefaultRouteName})
+
+		for _, route := range r.routes {
+			if route.name == group.Key {
+				routed[len(routed)-1].Breaker = route.breaker
+				routed[len(routed)-1].Destination = route.destination
+				routed[len(routed)-1].Route = route.name
+			}
+		}
+	}
+
+	return routed
+}
+
+// Mirror copies the message with the routing key to the mirrors.
+func (r *Router) Mirror(m *gelf.Message, key string) {
+	for _, mirror := range r.mirrors {
+		mirror.WriteMessage(m, key)
+	}
+}
+
+// Shutdown waits for the destinations being initialized in the background and writes the messages
+// queued by the mirrors until the context is done, then closes the connections of the mirrors
+// and the destinations of all routes.
+func (r *Router) Shutdown(ctx context.Context) error {
+	var errs []error
+
+	// Prevent initializing the destinations from now on.
+	r.connectOnce.Do(func() { close(r.connecting) })
+
+	for _, mirror := range r.mirrors {
+		if err := mirror.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	closed := make(chan error, 1)
+
+	// A connection being dialed is closed once the dial returns, which may take longer than the shutdown deadline.
+	go func() {
+		closed <- r.closeDestinations()
+	}()
+
+	select {
+	case err := <-closed:
+		errs = append(errs, err)
+	case <-ctx.Done():
+		r.logger.Warn("endpoints are still being closed on shutdown, closing them in the background")
+	}
+
+	return errors.Join(errs...)
+}
+
+// closeDestinations closes the destinations, which interrupts their initialization in the background if it is still in progress.
+func (r *Router) closeDestinations() error {
+	var errs []error
+
+	for _, destination := range r.destinations() {
+		if err := destination.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	<-r.connecting
+
+	return errors.Join(errs...)
+}
+
+// destinations returns the destinations of the routes, preceded by the default destination.
+func (r *Router) destinations() []*Destination {
+	destinations := []*Destination{r.destination}
+
+	for _, route := range r.routes {
+		destinations = append(destinations, route.destination)
+	}
+
+	return destinations
+}
+
+// matchResource returns the name of the route of all logs of the resource, which is empty for the default route.
+// It reports false if a route matching on log records has to be evaluated first.
+func (r *Router) matchResource(ctx context.Context, rl plog.ResourceLogs) (string, bool) {
+	for _, route := range r.routes {
+		if route.match.source == RoutingKeySourceLog {
+			return "", false
+		}
+
+		if route.match.matchResource(ctx, rl, r.logger) {
+			return route.name, true
+		}
+	}
+
+	return "", true
+}
+
+// matchRecord returns the name of the route of the log record, which is empty for the default route.
+func (r *Router) matchRecord(ctx context.Context, rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord) string {
+	for _, route := range r.routes {
+		if route.match.source == RoutingKeySourceLog {
+			if route.match.matchRecord(ctx, rl, sl, lr, r.logger) {
+				return route.name
+			}
+		} else if route.match.matchResource(ctx, rl, r.logger) {
+			return route.name
+		}
+	}
+
+	return ""
+}
+
+// routeMatcher evaluates the match configuration of a route.
+type routeMatcher struct {
+	attribute         string
+	logCondition      *ottl.Condition[ottllog.TransformContext]
+	regex             *regexp.Regexp
+	resourceCondition *ottl.Condition[ottlresource.TransformContext]
+	source            string
+	value             string
+}
+
+func newRouteMatcher(cfg *RouteMatchConfig, set component.TelemetrySettings) (*routeMatcher, error) {
+	var err error
+
+	m := &routeMatcher{
+		attribute: cfg.Attribute,
+		source:    cfg.Source,
+		value:     cfg.Value,
+	}
+
+	if m.source == "" {
+		m.source = RoutingKeySourceResource
+	}
+
+	if cfg.Regex != "" {
+		if m.regex, err = regexp.Compile(cfg.Regex); err != nil {
+			return nil, fmt.Errorf("invalid route match regex: %w", err)
+		}
+	}
+
+	if cfg.Condition == "" {
+		return m, nil
+	}
+
+	if m.source == RoutingKeySourceLog {
+		parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), set)
+
+		if err == nil {
+			m.logCondition, err = parser.ParseCondition(cfg.Condition)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid route match condition: %w", err)
+		}
+
+		return m, nil
+	}
+
+	parser, err := ottlresource.NewParser(ottlfuncs.StandardConverters[ottlresource.TransformContext](), set)
+
+	if err == nil {
+		m.resourceCondition, err = parser.ParseCondition(cfg.Condition)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid route match condition: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *routeMatcher) matchResource(ctx context.Context, rl plog.ResourceLogs, logger *zap.Logger) bool {
+	if m.resourceCondition == nil {
+		return m.matchAttribute(rl.Resource().Attributes().Get(m.attribute))
+	}
+
+	matched, err := m.resourceCondition.Eval(ctx, ottlresource.NewTransformContext(rl.Resource(), rl))
+
+	if err != nil {
+		logger.Debug("failed to evaluate route match condition", zap.Error(err))
+	}
+
+	return matched
+}
+
+func (m *routeMatcher) matchRecord(ctx context.Context, rl plog.ResourceLogs, sl plog.ScopeLogs, lr plog.LogRecord, logger *zap.Logger) bool {
+	if m.logCondition == nil {
+		return m.matchAttribute(lr.Attributes().Get(m.attribute))
+	}
+
+	matched, err := m.logCondition.Eval(ctx, ottllog.NewTransformContext(lr, sl.Scope(), rl.Resource(), sl, rl))
+
+	if err != nil {
+		logger.Debug("failed to evaluate route match condition", zap.Error(err))
+	}
+
+	return matched
+}
+
+func (m *routeMatcher) matchAttribute(value pcommon.Value, ok bool) bool {
+	if !ok {
+		return false
+	}
+
+	if m.regex != nil {
+		return m.regex.MatchString(value.AsString())
+	}
+
+	return value.AsString() == m.value
+}
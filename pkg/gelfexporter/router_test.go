@@ -0,0 +1,206 @@
+package gelfexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testTenantResources are the resources of three tenants, each holding an INFO and an ERROR log record.
+var testTenantResources = resourcesOf("tenant", []string{"acme", "globex", "initech"},
+	testRecord{severityText: "INFO"},
+	testRecord{severityText: "ERROR"},
+)
+
+func newTestRouter(t *testing.T, routes ...RouteConfig) *Router {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = "10.0.0.1:12201"
+	cfg.Routes = routes
+
+	require.NoError(t, cfg.Validate())
+
+	dialer, _ := testDialer()
+	r, err := NewRouter(cfg, func(*RouteEndpointTLS) Dialer { return dialer }, nil, exportertest.NewNopSettings(component.MustNewType(UdpExporterType)))
+	require.NoError(t, err)
+
+	return r
+}
+
+func routedRecordCounts(routed []RoutedLogs) map[string]int {
+	counts := make(map[string]int)
+
+	for _, logs := range routed {
+		counts[logs.Route] += logs.Logs.LogRecordCount()
+	}
+
+	return counts
+}
+
+func TestRouterWithoutRoutes(t *testing.T) {
+	r := newTestRouter(t)
+	ld := newTestLogs(testTenantResources...)
+
+	routed := r.Group(context.Background(), ld)
+	require.Len(t, routed, 1)
+	assert.Equal(t, DefaultRouteName, routed[0].Route)
+	assert.Equal(t, ld, routed[0].Logs)
+}
+
+func TestRouterGroup(t *testing.T) {
+	r := newTestRouter(t,
+		RouteConfig{
+			Name:     "acme",
+			Endpoint: "10.0.0.2:12201",
+			Match:    RouteMatchConfig{Attribute: "tenant", Value: "acme"},
+		},
+		RouteConfig{
+			Name:     "errors",
+			Endpoint: "10.0.0.3:12201",
+			Match:    RouteMatchConfig{Condition: `severity_text == "ERROR"`, Source: RoutingKeySourceLog},
+		},
+		RouteConfig{
+			Name:     "others",
+			Endpoint: "10.0.0.4:12201",
+			Match:    RouteMatchConfig{Attribute: "tenant", Regex: "^glob"},
+		},
+	)
+
+	routed := r.Group(context.Background(), newTestLogs(testTenantResources...))
+
+	assert.Equal(t, map[string]int{"acme": 2, "errors": 2, "others": 1, DefaultRouteName: 1}, routedRecordCounts(routed))
+	assert.Equal(t, "acme", routed[0].Route)
+	assert.Equal(t, "10.0.0.2:12201", routed[0].Destination.groups[0].String())
+
+	for _, logs := range routed {
+		if logs.Route == "errors" {
+			assert.Equal(t, 2, logs.Logs.ResourceLogs().Len())
+		}
+	}
+}
+
+func TestRouterGroupByResourceCondition(t *testing.T) {
+	r := newTestRouter(t, RouteConfig{
+		Name:     "initech",
+		Endpoint: "10.0.0.2:12201",
+		Match:    RouteMatchConfig{Condition: `attributes["tenant"] == "initech"`},
+	})
+
+	routed := r.Group(context.Background(), newTestLogs(testTenantResources...))
+
+	require.Len(t, routed, 2)
+	assert.Equal(t, DefaultRouteName, routed[0].Route)
+	assert.Equal(t, 2, routed[0].Logs.ResourceLogs().Len())
+	assert.Equal(t, "initech", routed[1].Route)
+	assert.Equal(t, 2, routed[1].Logs.LogRecordCount())
+}
+
+func newTestConnectConfig(connectOnStart string) *Config {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.ConnectOnStart = connectOnStart
+	cfg.Endpoint = "10.0.0.1:12201"
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointInitRetries = 1
+
+	return cfg
+}
+
+func newTestConnectRouter(t *testing.T, cfg *Config, dialer Dialer) *Router {
+	require.NoError(t, cfg.Validate())
+
+	r, err := NewRouter(cfg, func(*RouteEndpointTLS) Dialer { return dialer }, nil, exportertest.NewNopSettings(component.MustNewType(UdpExporterType)))
+	require.NoError(t, err)
+
+	return r
+}
+
+func TestRouterConnectInBackground(t *testing.T) {
+	release := make(chan struct{})
+	dialer, _ := testDialer()
+
+	r := newTestConnectRouter(t, newTestConnectConfig(ConnectOnStartBackground), func(address string) (gelf.Writer, error) {
+		<-release
+		return dialer(address)
+	})
+
+	require.True(t, r.Start())
+	assert.False(t, r.Ready())
+
+	close(release)
+
+	require.Eventually(t, r.Ready, time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestRouterConnectLazily(t *testing.T) {
+	var dials atomic.Int64
+
+	dialer, _ := testDialer()
+
+	r := newTestConnectRouter(t, newTestConnectConfig(ConnectOnStartLazy), func(address string) (gelf.Writer, error) {
+		dials.Add(1)
+		return dialer(address)
+	})
+
+	require.True(t, r.Start())
+	assert.Zero(t, dials.Load())
+
+	require.Eventually(t, r.Ready, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(1), dials.Load())
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestRouterShutdownReturnsOnDeadline(t *testing.T) {
+	release := make(chan struct{})
+	dialing := make(chan struct{})
+	dialer, _ := testDialer()
+
+	r := newTestConnectRouter(t, newTestConnectConfig(ConnectOnStartBackground), func(address string) (gelf.Writer, error) {
+		close(dialing)
+		<-release
+		return dialer(address)
+	})
+
+	defer close(release)
+
+	require.True(t, r.Start())
+	<-dialing
+
+	// The dial in progress holds the connection, so it can only be closed in the background.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	require.NoError(t, r.Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRouterShutdownInterruptsConnectInBackground(t *testing.T) {
+	var dials atomic.Int64
+
+	cfg := newTestConnectConfig(ConnectOnStartBackground)
+	cfg.EndpointInitBackoff = 60
+	cfg.EndpointInitRetries = 5
+
+	r := newTestConnectRouter(t, cfg, func(string) (gelf.Writer, error) {
+		dials.Add(1)
+		return nil, errors.New("connection refused")
+	})
+
+	require.True(t, r.Start())
+	require.Eventually(t, func() bool { return dials.Load() > 0 }, time.Second, time.Millisecond)
+
+	// The initialization waits a minute before retrying, so the shutdown is quick only if it interrupts it.
+	start := time.Now()
+
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, int64(1), dials.Load())
+}
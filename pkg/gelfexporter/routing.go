@@ -0,0 +1,206 @@
+package gelfexporter
+
+import (
+	ogcfactory "github.com/tomsobpl/otel-gelf-converter/pkg/factory"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+)
+
+// KeyedLogs are logs sharing the same routing key.
+type KeyedLogs struct {
+	Key  string
+	Logs plog.Logs
+}
+
+// Group splits the logs by the value of the routing key attribute, keeping their resource and scope.
+// Groups are returned in order of first appearance. Without an attribute configured,
+// the logs are returned as a single group with an empty key.
+func (cfg *RoutingKeyConfig) Group(ld plog.Logs) []KeyedLogs {
+	if cfg.Attribute == "" {
+		return []KeyedLogs{{Logs: ld}}
+	}
+
+	g := newLogGrouper()
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+
+		if cfg.Source == RoutingKeySourceResource {
+			g.addResource(attributeValue(rl.Resource().Attributes(), cfg.Attribute), rl)
+			continue
+		}
+
+		g.addRecords(rl, func(_ plog.ScopeLogs, lr plog.LogRecord) string {
+			return attributeValue(lr.Attributes(), cfg.Attribute)
+		})
+	}
+
+	return g.groups
+}
+
+// logGrouper collects logs into groups by key, in order of first appearance.
+type logGrouper struct {
+	groups  []KeyedLogs
+	indexes map[string]int
+}
+
+func newLogGrouper() *logGrouper {
+	return &logGrouper{indexes: make(map[string]int)}
+}
+
+// group returns the logs of the group with the key, creating it if needed.
+func (g *logGrouper) group(key string) plog.Logs {
+	if i, ok := g.indexes[key]; ok {
+		return g.groups[i].Logs
+	}
+
+	g.indexes[key] = len(g.groups)
+	g.groups = append(g.groups, KeyedLogs{Key: key, Logs: plog.NewLogs()})
+
+	return g.groups[len(g.groups)-1].Logs
+}
+
+// addResource adds all logs of the resource to the group with the key.
+func (g *logGrouper) addResource(key string, rl plog.ResourceLogs) {
+	rl.CopyTo(g.group(key).ResourceLogs().AppendEmpty())
+}
+
+// addRecords adds each log record of the resource to the group with its key,
+// recreating the resource and scope of the record in the group.
+func (g *logGrouper) addRecords(rl plog.ResourceLogs, recordKey func(plog.ScopeLogs, plog.LogRecord) string) {
+	resources := make(map[string]plog.ResourceLogs)
+
+	for j := 0; j < rl.ScopeLogs().Len(); j++ {
+		sl := rl.ScopeLogs().At(j)
+		scopes := make(map[string]plog.ScopeLogs)
+
+		for k := 0; k < sl.LogRecords().Len(); k++ {
+			lr := sl.LogRecords().At(k)
+			key := recordKey(sl, lr)
+
+			if _, ok := resources[key]; !ok {
+				resources[key] = g.group(key).ResourceLogs().AppendEmpty()
+				rl.Resource().CopyTo(resources[key].Resource())
+				resources[key].SetSchemaUrl(rl.SchemaUrl())
+			}
+
+			if _, ok := scopes[key]; !ok {
+				scopes[key] = resources[key].ScopeLogs().AppendEmpty()
+				sl.Scope().CopyTo(scopes[key].Scope())
+				scopes[key].SetSchemaUrl(sl.SchemaUrl())
+			}
+
+			lr.CopyTo(scopes[key].LogRecords().AppendEmpty())
+		}
+	}
+}
+
+// UnsentLogs returns the logs that are still to be written after a failure, so that only they are retried:
+// the log records of the failed logs after the first written ones, keeping their resource and scope,
+// followed by the remaining logs.
+func UnsentLogs(failed plog.Logs, written int, remaining ...plog.Logs) plog.Logs {
+	unsent := plog.NewLogs()
+
+	copyLogRecords(unsent, failed, func(i int) bool {
+		return i >= written
+	})
+
+	for _, ld := range remaining {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			ld.ResourceLogs().At(i).CopyTo(unsent.ResourceLogs().AppendEmpty())
+		}
+	}
+
+	return unsent
+}
+
+// RemainingLogs returns the logs of the groups and routes that were not written yet, in order.
+func RemainingLogs(groups []KeyedLogs, routed []RoutedLogs) []plog.Logs {
+	remaining := make([]plog.Logs, 0, len(groups)+len(routed))
+
+	for _, group := range groups {
+		remaining = append(remaining, group.Logs)
+	}
+
+	for _, r := range routed {
+		remaining = append(remaining, r.Logs)
+	}
+
+	return remaining
+}
+
+// RenderMessages returns the function rendering the GELF messages of logs with the factory, as written to the endpoints.
+func RenderMessages(factory *ogcfactory.Factory) func(plog.Logs) []*gelf.Message {
+	return func(ld plog.Logs) []*gelf.Message {
+		var messages []*gelf.Message
+
+		for _, m := range factory.FromOtelLogsData(ld) {
+			messages = append(messages, m.GetRawMessage())
+		}
+
+		return messages
+	}
+}
+
+// LogRecordsAt returns the log records at the indexes in the order of iterating over the logs,
+// keeping their resource and scope.
+func LogRecordsAt(ld plog.Logs, indexes []int) plog.Logs {
+	selected := make(map[int]bool, len(indexes))
+
+	for _, i := range indexes {
+		selected[i] = true
+	}
+
+	records := plog.NewLogs()
+
+	copyLogRecords(records, ld, func(i int) bool {
+		return selected[i]
+	})
+
+	return records
+}
+
+// copyLogRecords appends the log records of src whose index passes the filter to dst,
+// recreating only the resources and scopes of the copied records.
+func copyLogRecords(dst plog.Logs, src plog.Logs, filter func(i int) bool) {
+	var i int
+
+	for j := 0; j < src.ResourceLogs().Len(); j++ {
+		rl := src.ResourceLogs().At(j)
+		resource := plog.NewResourceLogs()
+
+		for k := 0; k < rl.ScopeLogs().Len(); k++ {
+			sl := rl.ScopeLogs().At(k)
+			scope := plog.NewScopeLogs()
+
+			for l := 0; l < sl.LogRecords().Len(); l, i = l+1, i+1 {
+				if !filter(i) {
+					continue
+				}
+
+				if resource.ScopeLogs().Len() == 0 {
+					resource = dst.ResourceLogs().AppendEmpty()
+					rl.Resource().CopyTo(resource.Resource())
+					resource.SetSchemaUrl(rl.SchemaUrl())
+				}
+
+				if scope.LogRecords().Len() == 0 {
+					scope = resource.ScopeLogs().AppendEmpty()
+					sl.Scope().CopyTo(scope.Scope())
+					scope.SetSchemaUrl(sl.SchemaUrl())
+				}
+
+				sl.LogRecords().At(l).CopyTo(scope.LogRecords().AppendEmpty())
+			}
+		}
+	}
+}
+
+func attributeValue(attributes pcommon.Map, name string) string {
+	if value, ok := attributes.Get(name); ok {
+		return value.AsString()
+	}
+
+	return ""
+}
@@ -0,0 +1,88 @@
+package gelfexporter
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// testServiceResources are the resources of two services, the first one twice, each holding log records of pods.
+var testServiceResources = resourcesOf("service.name", []string{"api", "worker", "api"},
+	testRecord{attributes: map[string]string{"k8s.pod.uid": "pod-a"}},
+	testRecord{attributes: map[string]string{"k8s.pod.uid": "pod-b"}},
+	testRecord{attributes: map[string]string{"k8s.pod.uid": "pod-a"}},
+)
+
+func TestRoutingKeyGroupDisabled(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	ld := newTestLogs(testServiceResources...)
+
+	groups := cfg.RoutingKey.Group(ld)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "", groups[0].Key)
+	assert.Equal(t, 9, groups[0].Logs.LogRecordCount())
+}
+
+func TestRoutingKeyGroupByResource(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.RoutingKey.Attribute = "service.name"
+
+	groups := cfg.RoutingKey.Group(newTestLogs(testServiceResources...))
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, "api", groups[0].Key)
+	assert.Equal(t, 2, groups[0].Logs.ResourceLogs().Len())
+	assert.Equal(t, 6, groups[0].Logs.LogRecordCount())
+
+	assert.Equal(t, "worker", groups[1].Key)
+	assert.Equal(t, 3, groups[1].Logs.LogRecordCount())
+}
+
+func TestRoutingKeyGroupByLog(t *testing.T) {
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.RoutingKey.Attribute = "k8s.pod.uid"
+	cfg.RoutingKey.Source = RoutingKeySourceLog
+
+	groups := cfg.RoutingKey.Group(newTestLogs(testServiceResources...))
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, "pod-a", groups[0].Key)
+	assert.Equal(t, 6, groups[0].Logs.LogRecordCount())
+	assert.Equal(t, 3, groups[0].Logs.ResourceLogs().Len())
+
+	rl := groups[0].Logs.ResourceLogs().At(1)
+	service, _ := rl.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "worker", service.AsString())
+	assert.Equal(t, testScopeName, rl.ScopeLogs().At(0).Scope().Name())
+	assert.Equal(t, 2, rl.ScopeLogs().At(0).LogRecords().Len())
+
+	assert.Equal(t, "pod-b", groups[1].Key)
+	assert.Equal(t, 3, groups[1].Logs.LogRecordCount())
+}
+
+func TestUnsentLogs(t *testing.T) {
+	unsent := UnsentLogs(newTestLogs(testServiceResources...), 4, newTestLogs(testServiceResources...))
+
+	assert.Equal(t, 14, unsent.LogRecordCount())
+	require.Equal(t, 5, unsent.ResourceLogs().Len())
+
+	rl := unsent.ResourceLogs().At(0)
+	service, _ := rl.Resource().Attributes().Get("service.name")
+	assert.Equal(t, "worker", service.Str())
+	assert.Equal(t, testScopeName, rl.ScopeLogs().At(0).Scope().Name())
+
+	pod, _ := rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("k8s.pod.uid")
+	assert.Equal(t, "pod-b", pod.Str())
+	assert.Equal(t, 2, rl.ScopeLogs().At(0).LogRecords().Len())
+}
+
+func TestLogRecordsAt(t *testing.T) {
+	records := LogRecordsAt(newTestLogs(testServiceResources...), []int{1, 2, 7})
+
+	assert.Equal(t, 3, records.LogRecordCount())
+	require.Equal(t, 2, records.ResourceLogs().Len())
+	assert.Equal(t, 2, records.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().Len())
+
+	service, _ := records.ResourceLogs().At(1).Resource().Attributes().Get("service.name")
+	assert.Equal(t, "api", service.Str())
+}
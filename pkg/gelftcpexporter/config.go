@@ -1,10 +1,15 @@
 package gelftcpexporter
 
 import (
+	"errors"
 	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
 	"go.opentelemetry.io/collector/component"
 )
 
+const (
+	FallbackTransportUDP string = "udp"
+)
+
 const (
 	DefaultEndpointTLSEnabled            = true
 	DefaultEndpointTLSInsecureSkipVerify = false
@@ -15,6 +20,9 @@ type Config struct {
 
 	// EndpointTLS is a configuration of the TLS connection.
 	EndpointTLS EndpointTLS `mapstructure:"endpoint_tls"`
+
+	// Fallback is a configuration of the transport used while the TCP endpoints are unavailable.
+	Fallback Fallback `mapstructure:"fallback"`
 }
 
 type EndpointTLS struct {
@@ -27,6 +35,45 @@ type EndpointTLS struct {
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
 }
 
+type Fallback struct {
+	// Enabled is a flag that enables or disables the fallback transport.
+	// It is used after the TCP endpoints, including the failover endpoints, failed to connect
+	// or failed Failover.WriteFailureThreshold writes in a row, until probing finds them available again.
+	// Default is false.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Endpoint is the address of the GELF input of the fallback transport.
+	// Default is empty, which means that the TCP endpoints are used.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Transport is the transport used as the fallback.
+	// The only possible value is "udp".
+	// Default value is "udp".
+	Transport string `mapstructure:"transport"`
+}
+
+func (cfg *Config) Validate() error {
+	if err := cfg.Config.Validate(); err != nil {
+		return err
+	}
+
+	return cfg.Fallback.Validate()
+}
+
+func (cfg *Fallback) Validate() error {
+	if cfg.Transport != FallbackTransportUDP {
+		return errors.New("invalid fallback transport")
+	}
+
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	endpoint := gelfexporter.EndpointConfig{Endpoint: cfg.Endpoint}
+
+	return endpoint.Validate()
+}
+
 func CreateDefaultConfig() component.Config {
 	return &Config{
 		Config: *gelfexporter.CreateDefaultConfig().(*gelfexporter.Config),
@@ -34,5 +81,8 @@ func CreateDefaultConfig() component.Config {
 			Enabled:            DefaultEndpointTLSEnabled,
 			InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
 		},
+		Fallback: Fallback{
+			Transport: FallbackTransportUDP,
+		},
 	}
 }
@@ -1,12 +1,15 @@
 package gelftcpexporter
 
 import (
+	"github.com/cenkalti/backoff/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/xconfmap"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"testing"
 )
 
@@ -22,64 +25,516 @@ func TestConfigLoading(t *testing.T) {
 			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), ""),
 			expected: &Config{
 				Config: gelfexporter.Config{
-					Endpoint:                "localhost:12201",
-					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
-					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
 					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
 					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
 				},
 				EndpointTLS: EndpointTLS{
 					Enabled:            DefaultEndpointTLSEnabled,
 					InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
 				},
+				Fallback: Fallback{
+					Transport: FallbackTransportUDP,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), "insecure"),
 			expected: &Config{
 				Config: gelfexporter.Config{
-					Endpoint:                "localhost:12201",
-					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
-					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
 					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
 					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
 				},
 				EndpointTLS: EndpointTLS{
 					Enabled:            false,
 					InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
 				},
+				Fallback: Fallback{
+					Transport: FallbackTransportUDP,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), "skipverify"),
 			expected: &Config{
 				Config: gelfexporter.Config{
-					Endpoint:                "localhost:12201",
-					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
-					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
 					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
 					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
 				},
 				EndpointTLS: EndpointTLS{
 					Enabled:            DefaultEndpointTLSEnabled,
 					InsecureSkipVerify: true,
 				},
+				Fallback: Fallback{
+					Transport: FallbackTransportUDP,
+				},
 			},
 		},
 		{
 			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), "custominit"),
 			expected: &Config{
 				Config: gelfexporter.Config{
-					Endpoint:                "localhost:12201",
-					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
-					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
 					EndpointInitBackoff:     15,
 					EndpointInitRetries:     7,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
+				},
+				EndpointTLS: EndpointTLS{
+					Enabled:            DefaultEndpointTLSEnabled,
+					InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
+				},
+				Fallback: Fallback{
+					Transport: FallbackTransportUDP,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), "routes"),
+			expected: &Config{
+				Config: gelfexporter.Config{
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
+					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
+					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					Routes: []gelfexporter.RouteConfig{
+						{
+							Endpoint: "localhost:12211",
+							EndpointTLS: gelfexporter.RouteEndpointTLS{
+								InsecureSkipVerify: func() *bool { v := true; return &v }(),
+							},
+							Match: gelfexporter.RouteMatchConfig{Attribute: "tenant", Value: "acme"},
+							Name:  "acme",
+						},
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
+				},
+				EndpointTLS: EndpointTLS{
+					Enabled:            DefaultEndpointTLSEnabled,
+					InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
+				},
+				Fallback: Fallback{
+					Transport: FallbackTransportUDP,
+				},
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(gelfexporter.TcpExporterType), "fallback"),
+			expected: &Config{
+				Config: gelfexporter.Config{
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
+					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
+					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
 				},
 				EndpointTLS: EndpointTLS{
 					Enabled:            DefaultEndpointTLSEnabled,
 					InsecureSkipVerify: DefaultEndpointTLSInsecureSkipVerify,
 				},
+				Fallback: Fallback{
+					Enabled:   true,
+					Endpoint:  "localhost:12202",
+					Transport: FallbackTransportUDP,
+				},
 			},
 		},
 	}
@@ -122,6 +577,26 @@ func TestConfigValidation(t *testing.T) {
 			}(),
 			wantErr: "invalid endpoint refresh strategy",
 		},
+		{
+			name: "InvalidFallbackTransport",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Fallback.Transport = "http"
+				return cfg
+			}(),
+			wantErr: "invalid fallback transport",
+		},
+		{
+			name: "InvalidFallbackEndpoint",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.Fallback.Endpoint = "srv://_gelf._udp.example.com:12201"
+				return cfg
+			}(),
+			wantErr: "SRV endpoint must be a plain DNS name",
+		},
 		{
 			name: "Success",
 			cfg: func() *Config {
@@ -2,175 +2,212 @@ package gelftcpexporter
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
 	ogc "github.com/tomsobpl/otel-gelf-converter/pkg"
 	ogcfactory "github.com/tomsobpl/otel-gelf-converter/pkg/factory"
 	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
-	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelftcpexporter/internal/tlsgateway"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelftcpexporter/internal/metadata"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 	"gopkg.in/Graylog2/go-gelf.v2/gelf"
-	"sync"
-	"time"
 )
 
+const transportTCP = "tcp"
+
 type gelfTcpExporter struct {
-	config                    *Config
-	logger                    *zap.Logger
-	messageFactory            *ogcfactory.Factory
-	writer                    *gelf.TCPWriter
-	writerEndpoint            string
-	writerEndpointRefreshTime int64
-	writerLock                sync.Mutex
-	writerTLSGateway          *tlsgateway.TLSGateway
+	config         *Config
+	deadLetter     *gelfexporter.DeadLetter
+	inflight       *gelfexporter.InflightLimiter
+	limiter        *gelfexporter.RateLimiter
+	logger         *zap.Logger
+	messageFactory *ogcfactory.Factory
+	router         *gelfexporter.Router
+	transitions    metric.Int64Counter
 }
 
-func newGelfTcpExporter(cfg component.Config, set exporter.Settings) *gelfTcpExporter {
-	return &gelfTcpExporter{
+func newGelfTcpExporter(cfg component.Config, set exporter.Settings) (*gelfTcpExporter, error) {
+	var err error
+
+	e := &gelfTcpExporter{
 		config:         cfg.(*Config),
+		inflight:       gelfexporter.NewInflightLimiter(&cfg.(*Config).Config),
 		logger:         set.Logger,
 		messageFactory: ogc.CreateFactory(set.Logger),
 	}
-}
-
-func (e *gelfTcpExporter) initGelfWriter() bool {
-	e.logger.Info(fmt.Sprintf("initializing GELF writer for endpoint %s", e.config.Endpoint))
 
-	var err = e.resolveWriterEndpoint()
+	e.deadLetter = gelfexporter.NewDeadLetter(&e.config.DeadLetter, gelfexporter.RenderMessages(e.messageFactory), set.Logger)
+	e.transitions, err = set.MeterProvider.Meter(metadata.ScopeName).Int64Counter(
+		"otelcol_exporter_gelf_transport_transitions",
+		metric.WithDescription("Number of switches between the TCP transport and the fallback transport"),
+		metric.WithUnit("{transitions}"),
+	)
 
 	if err != nil {
-		e.logger.Error(fmt.Sprintf("failed to resolve IP address for %s", e.config.Endpoint), zap.Error(err))
-		return false
+		return nil, err
 	}
 
-	writerEndpoint := e.writerEndpoint
-
-	if e.config.EndpointTLS.Enabled {
-		e.logger.Info("starting GELF TCP exporter TLS Proxy")
-
-		srcEndpoint := tlsgateway.Endpoint{Network: "tcp", Endpoint: "127.0.0.1:"}
-		dstEndpoint := tlsgateway.Endpoint{Network: "tcp", Endpoint: writerEndpoint}
-		gateway, err := tlsgateway.NewTLSGateway(srcEndpoint, dstEndpoint, e.logger)
-
-		if err != nil {
-			e.logger.Error("failed to start local listener", zap.Error(err))
-			return false
-		}
-
-		writerEndpoint = gateway.Addr().String()
-		e.logger.Debug(fmt.Sprintf("started local listener on %s", writerEndpoint))
-
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: e.config.EndpointTLS.InsecureSkipVerify,
-		}
-
-		if err := gateway.Start(tlsConfig); err != nil {
-			e.logger.Error("failed to start TLS gateway", zap.Error(err))
-			return false
-		}
-
-		if e.writerTLSGateway != nil {
-			e.logger.Debug("shutting down previous TLSGateway")
-			if err := e.writerTLSGateway.Shutdown(); err != nil {
-				e.logger.Error("failed to shutdown previous TLSGateway", zap.Error(err))
-			}
-		}
+	if e.limiter, err = gelfexporter.NewRateLimiter(&e.config.RateLimit, set); err != nil {
+		return nil, err
+	}
 
-		e.writerTLSGateway = gateway
+	if e.router, err = gelfexporter.NewRouter(&e.config.Config, e.newDialer, e.newFallback(set.ID), set); err != nil {
+		return nil, err
 	}
 
-	e.writer, err = gelf.NewTCPWriter(writerEndpoint)
+	return e, nil
+}
 
-	if err != nil {
-		e.logger.Error(fmt.Sprintf("failed to initialize GELF writer for endpoint %s", e.config.Endpoint), zap.Error(err))
-		return false
+// newFallback returns the fallback transport, or nil if it is disabled.
+func (e *gelfTcpExporter) newFallback(id component.ID) *gelfexporter.Fallback {
+	if !e.config.Fallback.Enabled {
+		return nil
 	}
 
-	return e.writer != nil
+	return &gelfexporter.Fallback{
+		Dialer:   dialUDPGelfWriter,
+		Endpoint: e.config.Fallback.Endpoint,
+		OnTransition: func(transport string) {
+			e.transitions.Add(context.Background(), 1, metric.WithAttributes(
+				attribute.String("exporter", id.String()),
+				attribute.String("transport", transport),
+			))
+		},
+		PrimaryTransport: transportTCP,
+		Transport:        e.config.Fallback.Transport,
+	}
 }
 
-func (e *gelfTcpExporter) initGelfWriterWithRetryAttempts() bool {
-	var i int
-	var initialized bool
-	var initBackoff = time.Duration(e.config.EndpointInitBackoff) * time.Second
+func dialUDPGelfWriter(address string) (gelf.Writer, error) {
+	return gelf.NewUDPWriter(address)
+}
 
-	e.writerLock.Lock()
+// newDialer returns the dialer using the TLS settings of the exporter, with the overrides of a route or a mirror applied.
+func (e *gelfTcpExporter) newDialer(override *gelfexporter.RouteEndpointTLS) gelfexporter.Dialer {
+	endpointTLS := e.config.EndpointTLS
 
-	for i = 0; i < e.config.EndpointInitRetries; i++ {
-		if initialized = e.initGelfWriter(); initialized {
-			break
-		}
+	if override != nil && override.Enabled != nil {
+		endpointTLS.Enabled = *override.Enabled
+	}
 
-		e.logger.Debug(fmt.Sprintf("retrying to initialize GELF writer in %s", initBackoff.String()))
-		time.Sleep(initBackoff)
+	if override != nil && override.InsecureSkipVerify != nil {
+		endpointTLS.InsecureSkipVerify = *override.InsecureSkipVerify
 	}
 
-	e.writerLock.Unlock()
+	return func(address string) (gelf.Writer, error) {
+		return e.dialGelfWriter(address, endpointTLS)
+	}
+}
 
-	if !initialized && i > e.config.EndpointInitRetries {
-		e.logger.Error(fmt.Sprintf("failed to initialize GELF writer after %d retries", e.config.EndpointInitRetries))
+func (e *gelfTcpExporter) dialGelfWriter(address string, endpointTLS EndpointTLS) (gelf.Writer, error) {
+	if !endpointTLS.Enabled {
+		return gelf.NewTCPWriter(address)
 	}
 
-	return initialized
+	return NewTLSWriter(address, endpointTLS.InsecureSkipVerify, e.logger)
 }
 
 func (e *gelfTcpExporter) start(_ context.Context, _ component.Host) error {
 	e.logger.Info("starting GELF TCP exporter")
 
-	if !e.initGelfWriterWithRetryAttempts() {
+	if !e.router.Start() {
 		return fmt.Errorf("failed to start exporter")
 	}
 
 	return nil
 }
 
-func (e *gelfTcpExporter) pushLogs(_ context.Context, ld plog.Logs) error {
-	e.logger.Info(fmt.Sprintf("processing %d resource log(s) with %d log record(s)", ld.ResourceLogs().Len(), ld.LogRecordCount()))
+// shutdown closes the connections after the sending queue is drained,
+// giving the mirrors ShutdownTimeout to write the messages they still queue.
+func (e *gelfTcpExporter) shutdown(ctx context.Context) error {
+	e.logger.Info("shutting down GELF TCP exporter")
 
-	if e.config.EndpointRefreshStrategy == gelfexporter.EndpointRefreshStrategyInterval && e.endpointRefreshIntervalExpired() {
-		e.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", e.config.EndpointRefreshStrategy))
+	if e.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
 
-		if !e.initGelfWriterWithRetryAttempts() {
-			return fmt.Errorf("failed to refresh writer endpoint")
-		}
+		ctx, cancel = context.WithTimeout(ctx, e.config.ShutdownTimeout)
+		defer cancel()
 	}
 
-	for _, m := range e.messageFactory.FromOtelLogsData(ld) {
-		if e.config.EndpointRefreshStrategy == gelfexporter.EndpointRefreshStrategyPerMessage {
-			e.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", e.config.EndpointRefreshStrategy))
+	return errors.Join(e.router.Shutdown(ctx), e.deadLetter.Close())
+}
 
-			if !e.initGelfWriterWithRetryAttempts() {
-				return fmt.Errorf("failed to refresh writer endpoint")
-			}
+func (e *gelfTcpExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	e.logger.Info(fmt.Sprintf("processing %d resource log(s) with %d log record(s)", ld.ResourceLogs().Len(), ld.LogRecordCount()))
+
+	if !e.router.Ready() {
+		return &gelfexporter.ConnectionError{Err: errors.New("GELF writer endpoints are not initialized yet")}
+	}
+
+	var permanent []error
+
+	routedLogs := e.router.Group(ctx, ld)
+
+	for i, routed := range routedLogs {
+		unsent := routed.Logs
+		err := gelfexporter.RetryAfterCoolDown(routed.Breaker.Allow())
+
+		if err == nil {
+			unsent, err = e.writeRoutedLogs(ctx, routed, &permanent)
+			routed.Breaker.Report(err)
 		}
 
-		if err := e.writer.WriteMessage(m.GetRawMessage()); err != nil {
-			e.logger.Error("failed to write message")
-			return err
+		if err != nil {
+			return consumererror.NewLogs(err, gelfexporter.UnsentLogs(unsent, 0, gelfexporter.RemainingLogs(nil, routedLogs[i+1:])...))
 		}
 	}
 
+	if len(permanent) > 0 {
+		return consumererror.NewPermanent(fmt.Errorf("failed to write %d message(s): %w", len(permanent), errors.Join(permanent...)))
+	}
+
 	return nil
 }
 
-func (e *gelfTcpExporter) endpointRefreshIntervalExpired() bool {
-	return time.Now().Unix()-e.writerEndpointRefreshTime > e.config.EndpointRefreshInterval
-}
+// writeRoutedLogs writes the logs of a route, returning the logs that were not written if it fails.
+// Messages failing permanently are written to the dead letter and skipped, adding their errors to permanent,
+// as they would fail the same way on every retry.
+// Messages over the rate limit are dropped unless the limiter blocks, which fails the unsent logs if the batch times out.
+// With an in-flight limit, the logs are converted and written in chunks, failing the unsent logs once a chunk exceeds it.
+func (e *gelfTcpExporter) writeRoutedLogs(ctx context.Context, routed gelfexporter.RoutedLogs, permanent *[]error) (plog.Logs, error) {
+	if !routed.Destination.Refresh() {
+		return routed.Logs, &gelfexporter.ConnectionError{Err: fmt.Errorf("failed to refresh writer endpoint of route %s", routed.Route)}
+	}
 
-func (e *gelfTcpExporter) resolveWriterEndpoint() error {
-	endpoint, err := gelfexporter.ResolveEndpoint(e.config.Endpoint)
+	groups := e.config.RoutingKey.Group(routed.Logs)
+	logger := e.logger.With(zap.String("route", routed.Route))
+	render := gelfexporter.RenderMessages(e.messageFactory)
 
-	if err != nil {
-		return err
-	}
+	for j, group := range groups {
+		sent, err := e.inflight.WriteChunks(ctx, group.Logs, e.limiter.Logs(group.Logs), render, logger, func(_ int, m *gelf.Message) error {
+			err := gelfexporter.ClassifyError(routed.Destination.WriteMessage(m, group.Key))
 
-	e.writerEndpoint = endpoint
-	e.writerEndpointRefreshTime = time.Now().Unix()
+			if err == nil {
+				e.router.Mirror(m, group.Key)
+				return nil
+			}
 
-	e.logger.Debug(fmt.Sprintf("resolved Endpoint %s into %s", e.config.Endpoint, e.writerEndpoint))
+			logger.Error("failed to write message", zap.Error(err))
 
-	return nil
+			if !consumererror.IsPermanent(err) {
+				return err
+			}
+
+			e.deadLetter.Write(m, err)
+			*permanent = append(*permanent, err)
+
+			return nil
+		})
+
+		if err != nil {
+			return gelfexporter.UnsentLogs(group.Logs, sent, gelfexporter.RemainingLogs(groups[j+1:], nil)...), err
+		}
+	}
+
+	return plog.NewLogs(), nil
 }
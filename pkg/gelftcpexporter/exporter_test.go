@@ -0,0 +1,276 @@
+package gelftcpexporter
+
+import (
+	"context"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/filestorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelftcpexporter/internal/metadata"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/internal/gelftest"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testHost provides the storage extension to the exporter.
+type testHost struct {
+	extensions map[component.ID]component.Component
+}
+
+func (h *testHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func newTestLogs() plog.Logs {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("queued")
+
+	return ld
+}
+
+func TestExporterReportsUnsentLogs(t *testing.T) {
+	var bodies []string
+
+	for i := 1; i <= 250; i++ {
+		bodies = append(bodies, strconv.Itoa(i))
+	}
+
+	tests := []struct {
+		name             string
+		bodies           []string
+		failing          string
+		maxInflightBytes int64
+		wantUnsent       []string
+		wantWritten      []string
+	}{
+		{
+			name:        "Batch",
+			bodies:      []string{"1", "2", "3", "4"},
+			failing:     "3",
+			wantUnsent:  []string{"3", "4"},
+			wantWritten: []string{"1", "2"},
+		},
+		{
+			name:             "Chunks",
+			bodies:           bodies,
+			failing:          "150",
+			maxInflightBytes: 1 << 20,
+			wantUnsent:       bodies[149:],
+			wantWritten:      bodies[:149],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			set := exportertest.NewNopSettings(metadata.Type)
+			w := gelftest.NewFailingWriter(tt.failing)
+
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.Endpoint = "127.0.0.1:12201"
+			cfg.EndpointInitRetries = 1
+			cfg.EndpointTLS.Enabled = false
+			cfg.MaxInflightBytes = tt.maxInflightBytes
+
+			e, err := newGelfTcpExporter(cfg, set)
+			require.NoError(t, err)
+
+			e.router, err = gelfexporter.NewRouter(&cfg.Config, func(*gelfexporter.RouteEndpointTLS) gelfexporter.Dialer {
+				return func(string) (gelf.Writer, error) { return w, nil }
+			}, nil, set)
+			require.NoError(t, err)
+			require.NoError(t, e.start(ctx, &testHost{}))
+
+			t.Cleanup(func() {
+				assert.NoError(t, e.shutdown(ctx))
+			})
+
+			err = e.pushLogs(ctx, gelftest.NewBodyLogs(tt.bodies...))
+
+			var logsErr consumererror.Logs
+
+			require.ErrorAs(t, err, &logsErr)
+			assert.False(t, consumererror.IsPermanent(err))
+			assert.Equal(t, tt.wantUnsent, gelftest.LogBodies(logsErr.Data()))
+			assert.Equal(t, tt.wantWritten, w.Messages())
+		})
+	}
+}
+
+func TestExporterPersistentQueue(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	storageID := component.MustNewID("file_storage")
+
+	server := gelftest.StartTCPServer(t, "127.0.0.1:0", nil)
+	address := server.Addr()
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = address
+	cfg.EndpointInitBackoff = 0
+	cfg.EndpointInitRetries = 1
+	cfg.EndpointTLS.Enabled = false
+	cfg.QueueConfig.NumConsumers = 1
+	cfg.QueueConfig.StorageID = &storageID
+	cfg.RetryConfig.InitialInterval = time.Minute
+	cfg.RetryConfig.MaxInterval = time.Minute
+	cfg.RetryConfig.MaxElapsedTime = 0
+
+	core, logs := observer.New(zap.InfoLevel)
+
+	run := func(consume func(e exporter.Logs)) {
+		storageFactory := filestorage.NewFactory()
+		storageCfg := storageFactory.CreateDefaultConfig().(*filestorage.Config)
+		storageCfg.Directory = directory
+
+		storage, err := storageFactory.Create(ctx, extensiontest.NewNopSettings(storageFactory.Type()), storageCfg)
+		require.NoError(t, err)
+
+		host := &testHost{extensions: map[component.ID]component.Component{storageID: storage}}
+		require.NoError(t, storage.Start(ctx, host))
+
+		// The queue is stored under the ID of the exporter, which has to be the same after the restart.
+		set := exportertest.NewNopSettings(metadata.Type)
+		set.ID = component.NewID(metadata.Type)
+		set.Logger = zap.New(core)
+
+		e, err := NewFactory().CreateLogs(ctx, set, cfg)
+		require.NoError(t, err)
+		require.NoError(t, e.Start(ctx, host))
+
+		consume(e)
+
+		require.NoError(t, e.Shutdown(ctx))
+		require.NoError(t, storage.Shutdown(ctx))
+	}
+
+	// The batch can't be sent while the GELF input is down and stays in the queue on shutdown.
+	run(func(e exporter.Logs) {
+		server.Reset()
+
+		require.NoError(t, e.ConsumeLogs(ctx, newTestLogs()))
+
+		// Batches that weren't picked up by a consumer yet are not restored in this version of the queue.
+		require.Eventually(t, func() bool {
+			return logs.FilterMessage("failed to write message").Len() > 0
+		}, 10*time.Second, 50*time.Millisecond)
+	})
+
+	require.Zero(t, server.Received())
+
+	// After a restart the queued batch is sent once the GELF input is back.
+	server = gelftest.StartTCPServer(t, address, nil)
+
+	run(func(_ exporter.Logs) {
+		require.Eventually(t, func() bool {
+			return server.Received() == 1
+		}, 10*time.Second, 50*time.Millisecond)
+	})
+}
+
+func TestExporterShutdownFlushesQueue(t *testing.T) {
+	ctx := context.Background()
+	server := gelftest.StartTCPServer(t, "127.0.0.1:0", nil)
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = server.Addr()
+	cfg.EndpointInitRetries = 1
+	cfg.EndpointTLS.Enabled = false
+
+	e, err := NewFactory().CreateLogs(ctx, exportertest.NewNopSettings(metadata.Type), cfg)
+	require.NoError(t, err)
+	require.NoError(t, e.Start(ctx, &testHost{}))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, e.ConsumeLogs(ctx, newTestLogs()))
+	}
+
+	require.NoError(t, e.Shutdown(ctx))
+
+	require.Eventually(t, func() bool {
+		return server.Received() == 10
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestExporterFallsBackWhenTLSEndpointDrops(t *testing.T) {
+	ctx := context.Background()
+	server := gelftest.StartTCPServer(t, "127.0.0.1:0", gelftest.NewTLSConfig(t))
+	fallback := gelftest.StartUDPServer(t)
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = server.Addr()
+	cfg.EndpointInitRetries = 1
+	cfg.EndpointTLS.Enabled = true
+	cfg.EndpointTLS.InsecureSkipVerify = true
+	cfg.Failover.WriteFailureThreshold = 1
+	cfg.Fallback.Enabled = true
+	cfg.Fallback.Endpoint = fallback.Addr()
+
+	e, err := newGelfTcpExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	require.NoError(t, e.start(ctx, &testHost{}))
+
+	t.Cleanup(func() {
+		assert.NoError(t, e.shutdown(ctx))
+	})
+
+	require.NoError(t, e.pushLogs(ctx, newTestLogs()))
+
+	require.Eventually(t, func() bool {
+		return server.Received() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once the TLS gateway notices the dropped connection, writes fail and the exporter falls back to UDP.
+	server.Reset()
+
+	require.Eventually(t, func() bool {
+		return e.pushLogs(ctx, newTestLogs()) == nil && len(fallback.Messages()) > 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, int64(1), server.Received())
+}
+
+func TestExporterReconnectsWhenTLSEndpointRestarts(t *testing.T) {
+	ctx := context.Background()
+	tlsConfig := gelftest.NewTLSConfig(t)
+	server := gelftest.StartTCPServer(t, "127.0.0.1:0", tlsConfig)
+	address := server.Addr()
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.Endpoint = address
+	cfg.EndpointInitRetries = 1
+	cfg.EndpointTLS.Enabled = true
+	cfg.EndpointTLS.InsecureSkipVerify = true
+
+	e, err := newGelfTcpExporter(cfg, exportertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	require.NoError(t, e.start(ctx, &testHost{}))
+
+	t.Cleanup(func() {
+		assert.NoError(t, e.shutdown(ctx))
+	})
+
+	require.NoError(t, e.pushLogs(ctx, newTestLogs()))
+
+	require.Eventually(t, func() bool {
+		return server.Received() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The address is unchanged, but the TLS connection to the previous server has to be established again.
+	server.Reset()
+	server = gelftest.StartTCPServer(t, address, tlsConfig)
+
+	require.Eventually(t, func() bool {
+		return e.pushLogs(ctx, newTestLogs()) == nil && server.Received() > 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
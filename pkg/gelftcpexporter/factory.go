@@ -2,6 +2,7 @@ package gelftcpexporter
 
 import (
 	"context"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -21,7 +22,20 @@ func createLogsExporter(
 	ctx context.Context,
 	set exporter.Settings,
 	cfg component.Config) (exporter.Logs, error) {
-	e := newGelfTcpExporter(cfg, set)
+	e, err := newGelfTcpExporter(cfg, set)
 
-	return exporterhelper.NewLogs(ctx, set, cfg, e.pushLogs, exporterhelper.WithStart(e.start))
+	if err != nil {
+		return nil, err
+	}
+
+	return gelfexporter.NewLogs(
+		ctx,
+		set,
+		cfg,
+		&e.config.Config,
+		e.deadLetter,
+		e.pushLogs,
+		exporterhelper.WithStart(e.start),
+		exporterhelper.WithShutdown(e.shutdown),
+	)
 }
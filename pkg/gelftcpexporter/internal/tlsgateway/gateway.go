@@ -3,8 +3,18 @@ package tlsgateway
 import (
 	"context"
 	"crypto/tls"
+	"errors"
+	"fmt"
 	"go.uber.org/zap"
 	"net"
+	"sync/atomic"
+	"time"
+)
+
+// The delays between attempts to accept a connection after a failure, doubling up to the maximum.
+const (
+	acceptRetryMaxDelay = time.Second
+	acceptRetryMinDelay = 5 * time.Millisecond
 )
 
 type Endpoint struct {
@@ -15,7 +25,9 @@ type Endpoint struct {
 type TLSGateway struct {
 	cancel   context.CancelFunc
 	conn     net.Conn
+	done     chan struct{}
 	endpoint Endpoint
+	failed   atomic.Bool
 	listener net.Listener
 	logger   *zap.Logger
 }
@@ -24,6 +36,12 @@ func (g *TLSGateway) Addr() net.Addr {
 	return g.listener.Addr()
 }
 
+// Alive reports whether the gateway still forwards to the remote endpoint.
+// Once its TLS connection fails, the gateway stops and a new one has to be started.
+func (g *TLSGateway) Alive() bool {
+	return !g.failed.Load()
+}
+
 func (g *TLSGateway) Start(config *tls.Config) error {
 	var err error
 
@@ -33,6 +51,8 @@ func (g *TLSGateway) Start(config *tls.Config) error {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	g.done = make(chan struct{})
+
 	go g.run(ctx)
 
 	g.cancel = cancel
@@ -40,53 +60,111 @@ func (g *TLSGateway) Start(config *tls.Config) error {
 	return nil
 }
 
+// Shutdown stops accepting connections and waits until the data of the current connection is forwarded,
+// so the GELF writer has to be closed first.
 func (g *TLSGateway) Shutdown() error {
-	g.cancel()
-	return g.listener.Close()
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	// The listener is already closed if the gateway stopped after its TLS connection failed.
+	err := g.listener.Close()
+
+	if errors.Is(err, net.ErrClosed) {
+		err = nil
+	}
+
+	if g.done != nil {
+		<-g.done
+	}
+
+	return err
 }
 
+// run forwards the accepted connections one by one until the gateway is shut down or its listener is closed.
+// Failures to accept a connection, such as running out of file descriptors, are retried with a backoff.
+// Once the TLS connection fails, it closes the listener and the accepted connection,
+// so that the writes of the GELF writer and its attempts to reconnect fail instead of being lost.
 func (g *TLSGateway) run(ctx context.Context) {
+	var delay time.Duration
+
+	defer close(g.done)
+	defer g.closeConn()
+
 	for {
 		select {
 		case <-ctx.Done():
-			if err := g.conn.Close(); err != nil {
-				g.logger.Error("failed to close local connection", zap.Error(err))
-			}
-
 			return
 		default:
 			conn, err := g.listener.Accept()
 
 			if err != nil {
-				g.logger.Error("failed to accept connection", zap.Error(err))
+				if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+					return
+				}
+
+				delay = min(max(2*delay, acceptRetryMinDelay), acceptRetryMaxDelay)
+				g.logger.Error("failed to accept connection", zap.Error(err), zap.Duration("retry_in", delay))
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(delay):
+				}
+
+				continue
 			}
 
-			g.forward(conn, g.conn)
+			delay = 0
+			err = g.forward(conn, g.conn)
+
+			if err != nil {
+				g.logger.Error("lost connection to remote endpoint, stopping TLS gateway", zap.Error(err))
+				g.failed.Store(true)
+			}
 
 			if err := conn.Close(); err != nil {
 				g.logger.Error("failed to close remote connection", zap.Error(err))
 			}
+
+			if err != nil {
+				if err := g.listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+					g.logger.Error("failed to close listener", zap.Error(err))
+				}
+
+				return
+			}
 		}
 	}
 }
 
-func (g *TLSGateway) forward(src net.Conn, dst net.Conn) {
-	srcChannel := connectionIntoChannel(src)
-	dstChannel := connectionIntoChannel(dst)
+func (g *TLSGateway) closeConn() {
+	if err := g.conn.Close(); err != nil {
+		g.logger.Error("failed to close local connection", zap.Error(err))
+	}
+}
+
+// forward copies the data between the accepted connection and the TLS connection until either is closed.
+// It returns an error if the TLS connection failed, after which nothing can be forwarded anymore.
+func (g *TLSGateway) forward(src net.Conn, dst net.Conn) error {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	srcChannel := connectionIntoChannel(src, stop)
+	dstChannel := connectionIntoChannel(dst, stop)
 
 	for {
 		select {
 		case b1 := <-srcChannel:
 			if b1 == nil {
-				return
+				return nil
 			} else {
 				if _, err := dst.Write(b1); err != nil {
-					g.logger.Warn("failed to write to destination", zap.Error(err))
+					return fmt.Errorf("failed to write to destination: %w", err)
 				}
 			}
 		case b2 := <-dstChannel:
 			if b2 == nil {
-				return
+				return errors.New("destination closed the connection")
 			} else {
 				if _, err := src.Write(b2); err != nil {
 					g.logger.Warn("failed receiving from destination", zap.Error(err))
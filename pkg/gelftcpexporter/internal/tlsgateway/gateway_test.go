@@ -0,0 +1,81 @@
+package tlsgateway
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net"
+	"testing"
+	"time"
+)
+
+// newTestGateway returns a gateway connected to the returned remote end of a pipe instead of a TLS endpoint.
+func newTestGateway(t *testing.T) (*TLSGateway, net.Conn) {
+	g, err := NewTLSGateway(Endpoint{Network: "tcp", Endpoint: "127.0.0.1:0"}, Endpoint{}, zap.NewNop())
+	require.NoError(t, err)
+
+	local, remote := net.Pipe()
+	t.Cleanup(func() { _ = remote.Close() })
+
+	g.conn = local
+	g.done = make(chan struct{})
+
+	return g, remote
+}
+
+func TestGatewayStopsOnClosedListener(t *testing.T) {
+	g, _ := newTestGateway(t)
+
+	go g.run(context.Background())
+
+	require.NoError(t, g.listener.Close())
+
+	select {
+	case <-g.done:
+	case <-time.After(time.Second):
+		t.Fatal("gateway kept accepting connections on a closed listener")
+	}
+}
+
+func TestGatewayShutdown(t *testing.T) {
+	g, _ := newTestGateway(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+
+	go g.run(ctx)
+
+	require.NoError(t, g.Shutdown())
+}
+
+func TestGatewayStopsOnFailedRemoteConnection(t *testing.T) {
+	g, remote := newTestGateway(t)
+
+	go g.run(context.Background())
+
+	conn, err := net.Dial("tcp", g.Addr().String())
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.True(t, g.Alive())
+	require.NoError(t, remote.Close())
+
+	select {
+	case <-g.done:
+	case <-time.After(time.Second):
+		t.Fatal("gateway kept running after its remote connection failed")
+	}
+
+	assert.False(t, g.Alive())
+
+	// Both the accepted connection and the listener are closed, so that the GELF writer can't write anymore.
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+
+	_, err = net.Dial("tcp", g.Addr().String())
+	assert.Error(t, err)
+
+	assert.NoError(t, g.Shutdown())
+}
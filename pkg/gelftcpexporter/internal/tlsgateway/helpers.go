@@ -3,8 +3,9 @@ package tlsgateway
 import "net"
 
 // connectionIntoChannel creates a channel from a connection.
+// Reading stops once the stop channel is closed and the next read returns.
 // https://gist.github.com/cs8425/a742349a55596f1b251a
-func connectionIntoChannel(conn net.Conn) chan []byte {
+func connectionIntoChannel(conn net.Conn, stop <-chan struct{}) chan []byte {
 	c := make(chan []byte)
 
 	go func() {
@@ -16,10 +17,17 @@ func connectionIntoChannel(conn net.Conn) chan []byte {
 				res := make([]byte, n)
 				// Copy the buffer so it doesn't get changed while read by the recipient.
 				copy(res, b[:n])
-				c <- res
+				select {
+				case c <- res:
+				case <-stop:
+					return
+				}
 			}
 			if err != nil {
-				c <- nil
+				select {
+				case c <- nil:
+				case <-stop:
+				}
 				break
 			}
 		}
@@ -0,0 +1,78 @@
+package gelftcpexporter
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelftcpexporter/internal/tlsgateway"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+)
+
+// errTLSGatewayStopped is returned by the writes through a TLS gateway that lost its connection to the endpoint.
+var errTLSGatewayStopped = errors.New("TLS gateway lost the connection to the endpoint")
+
+// tlsGatewayWriter is a GELF TCP writer sending through a local TLS gateway.
+type tlsGatewayWriter struct {
+	*gelf.TCPWriter
+	gateway *tlsgateway.TLSGateway
+}
+
+// NewTLSWriter returns a GELF TCP writer sending to the address over TLS, through a local TLS gateway
+// connected to it, as the exporter does for endpoints with TLS enabled.
+func NewTLSWriter(address string, insecureSkipVerify bool, logger *zap.Logger) (gelf.Writer, error) {
+	logger.Info("starting GELF TCP exporter TLS Proxy")
+
+	srcEndpoint := tlsgateway.Endpoint{Network: "tcp", Endpoint: "127.0.0.1:"}
+	dstEndpoint := tlsgateway.Endpoint{Network: "tcp", Endpoint: address}
+	gateway, err := tlsgateway.NewTLSGateway(srcEndpoint, dstEndpoint, logger)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local listener: %w", err)
+	}
+
+	logger.Debug(fmt.Sprintf("started local listener on %s", gateway.Addr().String()))
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+
+	if err := gateway.Start(tlsConfig); err != nil {
+		shutdownTLSGateway(gateway, logger)
+		return nil, fmt.Errorf("failed to start TLS gateway: %w", err)
+	}
+
+	writer, err := gelf.NewTCPWriter(gateway.Addr().String())
+
+	if err != nil {
+		shutdownTLSGateway(gateway, logger)
+		return nil, err
+	}
+
+	return &tlsGatewayWriter{TCPWriter: writer, gateway: gateway}, nil
+}
+
+func shutdownTLSGateway(gateway *tlsgateway.TLSGateway, logger *zap.Logger) {
+	if err := gateway.Shutdown(); err != nil {
+		logger.Error("failed to shutdown TLSGateway", zap.Error(err))
+	}
+}
+
+// Alive reports whether the gateway still forwards to the endpoint, so that the writer is replaced once it doesn't.
+func (w *tlsGatewayWriter) Alive() bool {
+	return w.gateway.Alive()
+}
+
+// WriteMessage fails once the gateway lost its connection to the endpoint, without retrying to reconnect to it.
+func (w *tlsGatewayWriter) WriteMessage(m *gelf.Message) error {
+	if !w.gateway.Alive() {
+		return errTLSGatewayStopped
+	}
+
+	return w.TCPWriter.WriteMessage(m)
+}
+
+// Close closes the writer first, so that the gateway forwards everything written before shutting down.
+func (w *tlsGatewayWriter) Close() error {
+	return errors.Join(w.TCPWriter.Close(), w.gateway.Shutdown())
+}
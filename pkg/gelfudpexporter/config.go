@@ -0,0 +1,47 @@
+package gelfudpexporter
+
+import (
+	"errors"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+	"go.opentelemetry.io/collector/component"
+)
+
+const (
+	OnWriteErrorFail   string = "fail"
+	OnWriteErrorIgnore string = "ignore"
+	OnWriteErrorRetry  string = "retry"
+)
+
+type Config struct {
+	gelfexporter.Config `mapstructure:",squash"`
+
+	// OnWriteError is the policy applied to the messages of a batch that failed to be written.
+	// Possible values are "ignore", "retry" and "fail".
+	// Default value is "ignore".
+	// "ignore" means that the failures are only logged and the batch is reported as sent.
+	// "retry" means that the failed log records are returned to be retried, unless the failure is permanent.
+	// "fail" means that the batch is reported as permanently failed, without retrying, and the failed messages
+	// are written to the dead letter if it is enabled.
+	// All messages of the batch are written regardless of the policy, and their failures are reported together.
+	OnWriteError string `mapstructure:"on_write_error"`
+}
+
+func (cfg *Config) Validate() error {
+	if err := cfg.Config.Validate(); err != nil {
+		return err
+	}
+
+	switch cfg.OnWriteError {
+	case OnWriteErrorFail, OnWriteErrorIgnore, OnWriteErrorRetry:
+		return nil
+	}
+
+	return errors.New("invalid on write error policy")
+}
+
+func CreateDefaultConfig() component.Config {
+	return &Config{
+		Config:       *gelfexporter.CreateDefaultConfig().(*gelfexporter.Config),
+		OnWriteError: OnWriteErrorIgnore,
+	}
+}
@@ -0,0 +1,241 @@
+package gelfudpexporter
+
+import (
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.opentelemetry.io/collector/confmap/xconfmap"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"testing"
+)
+
+func TestConfigLoading(t *testing.T) {
+	cm, err := confmaptest.LoadConf("testdata/config.yaml")
+	require.NoError(t, err)
+
+	tests := []struct {
+		id       component.ID
+		expected component.Config
+	}{
+		{
+			id: component.NewIDWithName(component.MustNewType(gelfexporter.UdpExporterType), ""),
+			expected: &Config{
+				Config: gelfexporter.Config{
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
+					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
+					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
+				},
+				OnWriteError: OnWriteErrorIgnore,
+			},
+		},
+		{
+			id: component.NewIDWithName(component.MustNewType(gelfexporter.UdpExporterType), "retry"),
+			expected: &Config{
+				Config: gelfexporter.Config{
+					CircuitBreaker: gelfexporter.CircuitBreakerConfig{
+						CoolDown:         gelfexporter.DefaultCircuitBreakerCoolDown,
+						FailureThreshold: gelfexporter.DefaultCircuitBreakerFailures,
+						SuccessThreshold: gelfexporter.DefaultCircuitBreakerSuccesses,
+					},
+					ConnectOnStart: gelfexporter.ConnectOnStartBlocking,
+					DeadLetter: gelfexporter.DeadLetterConfig{
+						MaxBackups: gelfexporter.DefaultDeadLetterMaxBackups,
+						MaxSize:    gelfexporter.DefaultDeadLetterMaxSize,
+					},
+					Endpoint: "localhost:12201",
+					EndpointBackoff: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultEndpointBackoffInitial,
+						MaxElapsedTime:      gelfexporter.DefaultEndpointBackoffMaxElapsed,
+						MaxInterval:         gelfexporter.DefaultEndpointBackoffMaxInterval,
+						Multiplier:          gelfexporter.DefaultEndpointBackoffMultiplier,
+						RandomizationFactor: gelfexporter.DefaultEndpointBackoffJitter,
+					},
+					EndpointInitBackoff:     gelfexporter.DefaultEndpointInitBackoff,
+					EndpointInitRetries:     gelfexporter.DefaultEndpointInitRetries,
+					EndpointRefreshInterval: gelfexporter.DefaultEndpointRefreshInterval,
+					EndpointRefreshMaxTTL:   gelfexporter.DefaultEndpointRefreshMaxTTL,
+					EndpointRefreshMinTTL:   gelfexporter.DefaultEndpointRefreshMinTTL,
+					EndpointRefreshStrategy: gelfexporter.EndpointRefreshStrategyNone,
+					Failover: gelfexporter.FailoverConfig{
+						ProbeInterval:         gelfexporter.DefaultFailoverProbeInterval,
+						WriteFailureThreshold: gelfexporter.DefaultFailoverWriteFailures,
+					},
+					IPFamily: gelfexporter.IPFamilyAny,
+					LoadBalancing: gelfexporter.LoadBalancingConfig{
+						EjectionDuration:  gelfexporter.DefaultEjectionDuration,
+						EjectionThreshold: gelfexporter.DefaultEjectionThreshold,
+						Strategy:          gelfexporter.LoadBalancingRoundRobin,
+					},
+					Priority: gelfexporter.PriorityConfig{
+						ShedThresholds: gelfexporter.PriorityShedThresholds{
+							Debug: gelfexporter.DefaultPriorityShedDebug,
+							Info:  gelfexporter.DefaultPriorityShedInfo,
+							Warn:  gelfexporter.DefaultPriorityShedWarn,
+						},
+					},
+					QueueConfig: exporterhelper.QueueConfig{
+						Enabled:      true,
+						NumConsumers: gelfexporter.DefaultQueueNumConsumers,
+						QueueSize:    gelfexporter.DefaultQueueSize,
+					},
+					RateLimit: gelfexporter.RateLimitConfig{
+						Action:      gelfexporter.RateLimitActionDrop,
+						MinSeverity: gelfexporter.DefaultRateLimitMinSeverity,
+					},
+					Resolver: gelfexporter.ResolverConfig{
+						Protocol: gelfexporter.ResolverProtocolUDP,
+						Timeout:  gelfexporter.DefaultResolverTimeout,
+					},
+					RetryConfig: configretry.BackOffConfig{
+						Enabled:             true,
+						InitialInterval:     gelfexporter.DefaultRetryInitialInterval,
+						MaxElapsedTime:      gelfexporter.DefaultRetryMaxElapsedTime,
+						MaxInterval:         gelfexporter.DefaultRetryMaxInterval,
+						Multiplier:          backoff.DefaultMultiplier,
+						RandomizationFactor: backoff.DefaultRandomizationFactor,
+					},
+					RoutingKey: gelfexporter.RoutingKeyConfig{
+						Source: gelfexporter.RoutingKeySourceResource,
+					},
+					ShutdownTimeout: gelfexporter.DefaultShutdownTimeout,
+					TimeoutConfig: exporterhelper.TimeoutConfig{
+						Timeout: gelfexporter.DefaultTimeout,
+					},
+				},
+				OnWriteError: OnWriteErrorRetry,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id.String(), func(t *testing.T) {
+			cfg := CreateDefaultConfig()
+
+			sub, err := cm.Sub(tt.id.String())
+			require.NoError(t, err)
+			require.NoError(t, sub.Unmarshal(cfg))
+
+			assert.NoError(t, xconfmap.Validate(cfg))
+			assert.Equal(t, tt.expected, cfg)
+		})
+	}
+}
+
+func TestConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr string
+	}{
+		{
+			name: "NoEndpoint",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				return cfg
+			}(),
+			wantErr: "GELF input endpoint must be specified",
+		},
+		{
+			name: "InvalidOnWriteError",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				cfg.OnWriteError = "drop"
+				return cfg
+			}(),
+			wantErr: "invalid on write error policy",
+		},
+		{
+			name: "Success",
+			cfg: func() *Config {
+				cfg := CreateDefaultConfig().(*Config)
+				cfg.Endpoint = "localhost:12201"
+				return cfg
+			}(),
+			wantErr: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,130 +2,223 @@ package gelfudpexporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	ogc "github.com/tomsobpl/otel-gelf-converter/pkg"
 	ogcfactory "github.com/tomsobpl/otel-gelf-converter/pkg/factory"
 	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 	"gopkg.in/Graylog2/go-gelf.v2/gelf"
-	"sync"
-	"time"
 )
 
 type gelfUdpExporter struct {
-	config                    *gelfexporter.Config
-	logger                    *zap.Logger
-	messageFactory            *ogcfactory.Factory
-	writer                    *gelf.UDPWriter
-	writerEndpoint            string
-	writerEndpointRefreshTime int64
-	writerLock                sync.Mutex
+	config         *Config
+	deadLetter     *gelfexporter.DeadLetter
+	inflight       *gelfexporter.InflightLimiter
+	limiter        *gelfexporter.RateLimiter
+	logger         *zap.Logger
+	messageFactory *ogcfactory.Factory
+	router         *gelfexporter.Router
 }
 
-func newGelfUdpExporter(cfg component.Config, set exporter.Settings) *gelfUdpExporter {
-	return &gelfUdpExporter{
-		config:         cfg.(*gelfexporter.Config),
-		logger:         set.Logger,
-		messageFactory: ogc.CreateFactory(set.Logger),
+func newGelfUdpExporter(cfg component.Config, set exporter.Settings) (*gelfUdpExporter, error) {
+	config := cfg.(*Config)
+	limiter, err := gelfexporter.NewRateLimiter(&config.RateLimit, set)
+
+	if err != nil {
+		return nil, err
 	}
-}
 
-func (e *gelfUdpExporter) initGelfWriter() bool {
-	e.logger.Info(fmt.Sprintf("initializing GELF writer for endpoint %s", e.config.Endpoint))
-	var err error
+	router, err := gelfexporter.NewRouter(&config.Config, newDialer, nil, set)
 
-	if err = e.resolveWriterEndpoint(); err != nil {
-		e.logger.Error(fmt.Sprintf("failed to resolve IP address for %s", e.config.Endpoint), zap.Error(err))
-		return false
+	if err != nil {
+		return nil, err
 	}
 
-	if e.writer, err = gelf.NewUDPWriter(e.writerEndpoint); err != nil {
-		e.logger.Error(fmt.Sprintf("failed to initialize GELF writer for endpoint %s", e.config.Endpoint), zap.Error(err))
-		return false
+	e := &gelfUdpExporter{
+		config:         config,
+		inflight:       gelfexporter.NewInflightLimiter(&config.Config),
+		limiter:        limiter,
+		logger:         set.Logger,
+		messageFactory: ogc.CreateFactory(set.Logger),
+		router:         router,
 	}
 
-	return e.writer != nil
+	e.deadLetter = gelfexporter.NewDeadLetter(&config.DeadLetter, gelfexporter.RenderMessages(e.messageFactory), set.Logger)
+
+	return e, nil
 }
 
-func (e *gelfUdpExporter) initGelfWriterWithRetryAttempts() bool {
-	var i int
-	var initialized bool
-	var initBackoff = time.Duration(e.config.EndpointInitBackoff) * time.Second
+func newDialer(_ *gelfexporter.RouteEndpointTLS) gelfexporter.Dialer {
+	return dialGelfWriter
+}
 
-	e.writerLock.Lock()
+func dialGelfWriter(address string) (gelf.Writer, error) {
+	return gelf.NewUDPWriter(address)
+}
 
-	for i = 0; i < e.config.EndpointInitRetries; i++ {
-		if initialized = e.initGelfWriter(); initialized {
-			break
-		}
+func (e *gelfUdpExporter) start(_ context.Context, _ component.Host) error {
+	e.logger.Info("starting GELF UDP exporter")
 
-		e.logger.Debug(fmt.Sprintf("retrying to initialize GELF writer in %s", initBackoff.String()))
-		time.Sleep(initBackoff)
+	if !e.router.Start() {
+		return fmt.Errorf("failed to start exporter")
 	}
 
-	e.writerLock.Unlock()
+	return nil
+}
+
+// shutdown closes the connections after the sending queue is drained,
+// giving the mirrors ShutdownTimeout to write the messages they still queue.
+func (e *gelfUdpExporter) shutdown(ctx context.Context) error {
+	e.logger.Info("shutting down GELF UDP exporter")
+
+	if e.config.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
 
-	if !initialized && i > e.config.EndpointInitRetries {
-		e.logger.Error(fmt.Sprintf("failed to initialize GELF writer after %d retries", e.config.EndpointInitRetries))
+		ctx, cancel = context.WithTimeout(ctx, e.config.ShutdownTimeout)
+		defer cancel()
 	}
 
-	return initialized
+	return errors.Join(e.router.Shutdown(ctx), e.deadLetter.Close())
 }
 
-func (e *gelfUdpExporter) start(_ context.Context, _ component.Host) error {
-	e.logger.Info("starting GELF UDP exporter")
+func (e *gelfUdpExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	var messages int
+	var permanent, retryable []error
 
-	if !e.initGelfWriterWithRetryAttempts() {
-		return fmt.Errorf("failed to start exporter")
+	failed := plog.NewLogs()
+	render := gelfexporter.RenderMessages(e.messageFactory)
+
+	e.logger.Info(fmt.Sprintf("processing %d resource log(s) with %d log record(s)", ld.ResourceLogs().Len(), ld.LogRecordCount()))
+
+	if !e.router.Ready() {
+		return &gelfexporter.ConnectionError{Err: errors.New("GELF writer endpoints are not initialized yet")}
 	}
 
-	return nil
-}
+	routedLogs := e.router.Group(ctx, ld)
 
-func (e *gelfUdpExporter) pushLogs(_ context.Context, ld plog.Logs) error {
-	e.logger.Info(fmt.Sprintf("processing %d resource log(s) with %d log record(s)", ld.ResourceLogs().Len(), ld.LogRecordCount()))
+	for i, routed := range routedLogs {
+		if err := gelfexporter.RetryAfterCoolDown(routed.Breaker.Allow()); err != nil {
+			unsent := gelfexporter.UnsentLogs(routed.Logs, 0, gelfexporter.RemainingLogs(nil, routedLogs[i+1:])...)
+			return e.unsentError(err, retryable, plog.NewLogs(), failed, unsent)
+		}
+
+		if !routed.Destination.Refresh() {
+			err := &gelfexporter.ConnectionError{Err: fmt.Errorf("failed to refresh writer endpoint of route %s", routed.Route)}
+			routed.Breaker.Report(err)
 
-	if e.config.EndpointRefreshStrategy == gelfexporter.EndpointRefreshStrategyInterval && e.endpointRefreshIntervalExpired() {
-		e.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", e.config.EndpointRefreshStrategy))
-		if !e.initGelfWriterWithRetryAttempts() {
-			return fmt.Errorf("failed to refresh writer endpoint")
+			unsent := gelfexporter.UnsentLogs(routed.Logs, 0, gelfexporter.RemainingLogs(nil, routedLogs[i+1:])...)
+			return e.unsentError(err, retryable, plog.NewLogs(), failed, unsent)
 		}
-	}
 
-	for _, m := range e.messageFactory.FromOtelLogsData(ld) {
-		if e.config.EndpointRefreshStrategy == gelfexporter.EndpointRefreshStrategyPerMessage {
-			e.logger.Debug(fmt.Sprintf("refreshing writer endpoint due to '%s' strategy", e.config.EndpointRefreshStrategy))
-			if !e.initGelfWriterWithRetryAttempts() {
-				return fmt.Errorf("failed to refresh writer endpoint")
+		routeFailures := len(retryable)
+
+		groups := e.config.RoutingKey.Group(routed.Logs)
+		logger := e.logger.With(zap.String("route", routed.Route))
+
+		for j, group := range groups {
+			var failedRecords []int
+
+			// refuse fails the logs from the k-th log record of the group on, which were not written because of a limit.
+			// The limit is reported to the breaker unless writes failed before, so that a trial cut short
+			// by a limit doesn't count as a success.
+			refuse := func(err error, k int) error {
+				unsent := gelfexporter.UnsentLogs(group.Logs, k, gelfexporter.RemainingLogs(groups[j+1:], routedLogs[i+1:])...)
+
+				if routeErr := errors.Join(retryable[routeFailures:]...); routeErr != nil {
+					routed.Breaker.Report(routeErr)
+				} else {
+					routed.Breaker.Report(err)
+				}
+
+				return e.unsentError(err, retryable, gelfexporter.LogRecordsAt(group.Logs, failedRecords), failed, unsent)
+			}
+
+			refusedAt, refused := e.inflight.WriteChunks(ctx, group.Logs, e.limiter.Logs(group.Logs), render, logger, func(i int, m *gelf.Message) error {
+				messages++
+
+				if err := gelfexporter.ClassifyError(routed.Destination.WriteMessage(m, group.Key)); err != nil {
+					logger.Error("failed to write message", zap.Error(err))
+
+					if consumererror.IsPermanent(err) {
+						e.deadLetter.Write(m, err)
+						permanent = append(permanent, err)
+					} else {
+						failedRecords = append(failedRecords, i)
+						retryable = append(retryable, err)
+					}
+
+					return nil
+				}
+
+				e.router.Mirror(m, group.Key)
+
+				return nil
+			})
+
+			// The log records of the group are only read once its chunks are processed, as they are moved out meanwhile.
+			if refused != nil {
+				return refuse(refused, refusedAt)
 			}
-		}
 
-		if err := e.writer.WriteMessage(m.GetRawMessage()); err != nil {
-			e.logger.Error("failed to write message", zap.Error(err))
+			if len(failedRecords) > 0 {
+				gelfexporter.LogRecordsAt(group.Logs, failedRecords).ResourceLogs().MoveAndAppendTo(failed.ResourceLogs())
+			}
 		}
+
+		// Permanent failures are caused by the messages, so only the retryable ones count against the route.
+		routed.Breaker.Report(errors.Join(retryable[routeFailures:]...))
 	}
 
-	return nil
+	return e.writeError(permanent, retryable, messages, failed)
 }
 
-func (e *gelfUdpExporter) endpointRefreshIntervalExpired() bool {
-	return time.Now().Unix()-e.writerEndpointRefreshTime > e.config.EndpointRefreshInterval
+// unsentError reports the logs not written because the batch was interrupted, by an open circuit,
+// an endpoint that failed to refresh or a limit, as retryable, so that they are written by a later attempt.
+// The log records that failed to be written before with retryable errors are reported with them according to
+// the OnWriteError policy: retried with the retry policy, and failing the whole batch permanently with the fail
+// policy, as writeError does, so that they are all written to the dead letter.
+func (e *gelfUdpExporter) unsentError(err error, retryable []error, failedRecords plog.Logs, failed plog.Logs, unsent plog.Logs) error {
+	if len(retryable) == 0 || e.config.OnWriteError == OnWriteErrorIgnore {
+		return consumererror.NewLogs(err, unsent)
+	}
+
+	logsErr := consumererror.NewLogs(errors.Join(append(retryable, err)...), gelfexporter.UnsentLogs(failed, 0, failedRecords, unsent))
+
+	if e.config.OnWriteError == OnWriteErrorFail {
+		return consumererror.NewPermanent(logsErr)
+	}
+
+	return logsErr
 }
 
-func (e *gelfUdpExporter) resolveWriterEndpoint() error {
-	endpoint, err := gelfexporter.ResolveEndpoint(e.config.Endpoint)
+// writeError reports the failures to write messages of a batch according to the OnWriteError policy.
+// The retry policy only returns the log records that failed with retryable errors,
+// as permanent failures would fail the same way again. The fail policy still reports the log records
+// that failed with retryable errors, so that they are written to the dead letter, while the messages
+// that failed permanently were written to it as they failed.
+func (e *gelfUdpExporter) writeError(permanent []error, retryable []error, messages int, failed plog.Logs) error {
+	failures := len(permanent) + len(retryable)
 
-	if err != nil {
-		return err
+	if failures == 0 || e.config.OnWriteError == OnWriteErrorIgnore {
+		return nil
 	}
 
-	e.writerEndpoint = endpoint
-	e.writerEndpointRefreshTime = time.Now().Unix()
+	if e.config.OnWriteError == OnWriteErrorFail || len(retryable) == 0 {
+		err := fmt.Errorf("failed to write %d of %d message(s): %w", failures, messages, errors.Join(append(permanent, retryable...)...))
 
-	e.logger.Debug(fmt.Sprintf("resolved Endpoint %s into %s", e.config.Endpoint, e.writerEndpoint))
+		if len(retryable) > 0 {
+			return consumererror.NewPermanent(consumererror.NewLogs(err, failed))
+		}
 
-	return nil
+		return consumererror.NewPermanent(err)
+	}
+
+	err := fmt.Errorf("failed to write %d of %d message(s), retrying %d: %w", failures, messages, len(retryable), errors.Join(retryable...))
+
+	return consumererror.NewLogs(err, failed)
 }
@@ -0,0 +1,322 @@
+package gelfudpexporter
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfexporter"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/gelfudpexporter/internal/metadata"
+	"github.com/tomsobpl/otel-gelf-exporter/pkg/internal/gelftest"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configretry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestExporter returns the started exporter, writing with the writer if it's not nil.
+func newTestExporter(t *testing.T, cfg *Config, writer gelf.Writer) *gelfUdpExporter {
+	t.Helper()
+
+	require.NoError(t, cfg.Validate())
+
+	set := exportertest.NewNopSettings(metadata.Type)
+	e, err := newGelfUdpExporter(cfg, set)
+	require.NoError(t, err)
+
+	if writer != nil {
+		e.router, err = gelfexporter.NewRouter(&cfg.Config, func(*gelfexporter.RouteEndpointTLS) gelfexporter.Dialer {
+			return func(string) (gelf.Writer, error) { return writer, nil }
+		}, nil, set)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, e.start(context.Background(), componenttest.NewNopHost()))
+
+	t.Cleanup(func() {
+		assert.NoError(t, e.shutdown(context.Background()))
+	})
+
+	return e
+}
+
+// newTenantLogs returns logs with a resource for each tenant holding a log record whose body is the tenant.
+func newTenantLogs(tenants ...string) plog.Logs {
+	ld := plog.NewLogs()
+
+	for _, tenant := range tenants {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("tenant", tenant)
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr(tenant)
+	}
+
+	return ld
+}
+
+func TestWriteErrorPolicy(t *testing.T) {
+	failed := plog.NewLogs()
+	failed.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+
+	permanent := []error{consumererror.NewPermanent(errors.New("msg too large"))}
+	retryable := []error{errors.New("connection refused")}
+
+	tests := []struct {
+		name         string
+		onWriteError string
+		permanent    []error
+		retryable    []error
+		wantErr      string
+		wantLogs     bool
+		wantRetry    bool
+	}{
+		{
+			name:         "Ignore",
+			onWriteError: OnWriteErrorIgnore,
+			permanent:    permanent,
+			retryable:    retryable,
+		},
+		{
+			name:         "Retry",
+			onWriteError: OnWriteErrorRetry,
+			permanent:    permanent,
+			retryable:    retryable,
+			wantErr:      "failed to write 2 of 10 message(s), retrying 1: connection refused",
+			wantLogs:     true,
+			wantRetry:    true,
+		},
+		{
+			name:         "RetryOnlyPermanent",
+			onWriteError: OnWriteErrorRetry,
+			permanent:    permanent,
+			wantErr:      "failed to write 1 of 10 message(s): Permanent error: msg too large",
+		},
+		{
+			name:         "Fail",
+			onWriteError: OnWriteErrorFail,
+			retryable:    retryable,
+			wantErr:      "failed to write 1 of 10 message(s): connection refused",
+			wantLogs:     true,
+		},
+		{
+			name:         "NoFailures",
+			onWriteError: OnWriteErrorFail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.OnWriteError = tt.onWriteError
+
+			e := &gelfUdpExporter{config: cfg, logger: zap.NewNop()}
+			err := e.writeError(tt.permanent, tt.retryable, 10, failed)
+
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Equal(t, !tt.wantRetry, consumererror.IsPermanent(err))
+
+			var logsErr consumererror.Logs
+
+			if assert.Equal(t, tt.wantLogs, errors.As(err, &logsErr)) && tt.wantLogs {
+				assert.Equal(t, 1, logsErr.Data().LogRecordCount())
+			}
+		})
+	}
+}
+
+func TestExporterOpenCircuitReportsUnsentRoutes(t *testing.T) {
+	primary := gelftest.StartUDPServer(t)
+	tenant := gelftest.StartUDPServer(t)
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.FailureThreshold = 1
+	cfg.Endpoint = primary.Addr()
+	cfg.Routes = []gelfexporter.RouteConfig{{
+		Endpoint: tenant.Addr(),
+		Match:    gelfexporter.RouteMatchConfig{Attribute: "tenant", Value: "b"},
+		Name:     "b",
+	}}
+
+	e := newTestExporter(t, cfg, nil)
+	ld := newTenantLogs("a", "b", "c")
+
+	routed := e.router.Group(context.Background(), ld)
+	require.Len(t, routed, 2)
+	require.NoError(t, routed[1].Breaker.Allow())
+	routed[1].Breaker.Report(errors.New("connection refused"))
+
+	err := e.pushLogs(context.Background(), ld)
+
+	var logsErr consumererror.Logs
+
+	require.ErrorAs(t, err, &logsErr)
+	assert.ErrorAs(t, err, new(*gelfexporter.CircuitOpenError))
+	assert.False(t, consumererror.IsPermanent(err))
+	assert.Equal(t, []string{"b"}, gelftest.LogBodies(logsErr.Data()))
+
+	assert.Eventually(t, func() bool {
+		return len(primary.Messages()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "c"}, primary.Messages())
+	assert.Empty(t, tenant.Messages())
+}
+
+func TestExporterReportsFailedLogs(t *testing.T) {
+	var tenants []string
+
+	for i := 1; i <= 250; i++ {
+		tenants = append(tenants, strconv.Itoa(i))
+	}
+
+	tests := []struct {
+		name             string
+		tenants          []string
+		failing          string
+		maxInflightBytes int64
+		wantWritten      []string
+	}{
+		{
+			name:        "Batch",
+			tenants:     []string{"a", "b", "c"},
+			failing:     "b",
+			wantWritten: []string{"a", "c"},
+		},
+		{
+			name:             "Chunks",
+			tenants:          tenants,
+			failing:          "150",
+			maxInflightBytes: 1 << 20,
+			wantWritten:      append(append([]string(nil), tenants[:149]...), tenants[150:]...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := gelftest.NewFailingWriter(tt.failing)
+
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.Endpoint = "127.0.0.1:12201"
+			cfg.MaxInflightBytes = tt.maxInflightBytes
+			cfg.OnWriteError = OnWriteErrorRetry
+
+			e := newTestExporter(t, cfg, w)
+			err := e.pushLogs(context.Background(), newTenantLogs(tt.tenants...))
+
+			var logsErr consumererror.Logs
+
+			require.ErrorAs(t, err, &logsErr)
+			assert.False(t, consumererror.IsPermanent(err))
+			assert.Equal(t, []string{tt.failing}, gelftest.LogBodies(logsErr.Data()))
+			assert.Equal(t, tt.wantWritten, w.Messages())
+		})
+	}
+}
+
+func TestExporterReportsFailedLogsWithRefusedLogs(t *testing.T) {
+	tests := []struct {
+		name          string
+		onWriteError  string
+		wantPermanent bool
+		wantUnsent    []string
+	}{
+		{
+			name:         "Ignore",
+			onWriteError: OnWriteErrorIgnore,
+			wantUnsent:   []string{"c"},
+		},
+		{
+			name:         "Retry",
+			onWriteError: OnWriteErrorRetry,
+			wantUnsent:   []string{"a", "c"},
+		},
+		{
+			name:          "Fail",
+			onWriteError:  OnWriteErrorFail,
+			wantPermanent: true,
+			wantUnsent:    []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := gelftest.NewFailingWriter("a")
+
+			// The third message would wait for the rate limit beyond the deadline of the batch.
+			cfg := CreateDefaultConfig().(*Config)
+			cfg.Endpoint = "127.0.0.1:12201"
+			cfg.OnWriteError = tt.onWriteError
+			cfg.RateLimit.Action = gelfexporter.RateLimitActionBlock
+			cfg.RateLimit.Enabled = true
+			cfg.RateLimit.MessagesPerSecond = 2
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			e := newTestExporter(t, cfg, w)
+			err := e.pushLogs(ctx, newTenantLogs("a", "b", "c"))
+
+			var logsErr consumererror.Logs
+
+			require.ErrorAs(t, err, &logsErr)
+			assert.ErrorAs(t, err, new(*gelfexporter.RateLimitError))
+			assert.Equal(t, tt.wantPermanent, consumererror.IsPermanent(err))
+			assert.Equal(t, tt.wantUnsent, gelftest.LogBodies(logsErr.Data()))
+			assert.Equal(t, []string{"b"}, w.Messages())
+		})
+	}
+}
+
+func TestExporterOpenCircuitDelaysRetry(t *testing.T) {
+	var attempts atomic.Int64
+
+	server := gelftest.StartUDPServer(t)
+
+	cfg := CreateDefaultConfig().(*Config)
+	cfg.CircuitBreaker.CoolDown = 200 * time.Millisecond
+	cfg.CircuitBreaker.Enabled = true
+	cfg.CircuitBreaker.FailureThreshold = 1
+	cfg.Endpoint = server.Addr()
+
+	e := newTestExporter(t, cfg, nil)
+	ld := newTenantLogs("a")
+
+	routed := e.router.Group(context.Background(), ld)
+	require.NoError(t, routed[0].Breaker.Allow())
+	routed[0].Breaker.Report(errors.New("connection refused"))
+
+	// Without the cool-down as the delay, the batch would be retried every millisecond until the circuit lets it through.
+	retrying, err := exporterhelper.NewLogs(context.Background(), exportertest.NewNopSettings(metadata.Type), cfg,
+		func(ctx context.Context, ld plog.Logs) error {
+			attempts.Add(1)
+			return e.pushLogs(ctx, ld)
+		},
+		exporterhelper.WithRetry(configretry.BackOffConfig{
+			Enabled:         true,
+			InitialInterval: time.Millisecond,
+			MaxElapsedTime:  5 * time.Second,
+			MaxInterval:     time.Millisecond,
+			Multiplier:      1,
+		}),
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, retrying.ConsumeLogs(context.Background(), ld))
+	assert.Equal(t, int64(2), attempts.Load())
+
+	assert.Eventually(t, func() bool {
+		return len(server.Messages()) == 1
+	}, time.Second, 10*time.Millisecond)
+}
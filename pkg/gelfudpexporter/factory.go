@@ -13,7 +13,7 @@ import (
 func NewFactory() exporter.Factory {
 	return exporter.NewFactory(
 		metadata.Type,
-		gelfexporter.CreateDefaultConfig,
+		CreateDefaultConfig,
 		exporter.WithLogs(createLogsExporter, metadata.ExporterStabilityLevel),
 	)
 }
@@ -22,7 +22,20 @@ func createLogsExporter(
 	ctx context.Context,
 	set exporter.Settings,
 	cfg component.Config) (exporter.Logs, error) {
-	e := newGelfUdpExporter(cfg, set)
+	e, err := newGelfUdpExporter(cfg, set)
 
-	return exporterhelper.NewLogs(ctx, set, cfg, e.pushLogs, exporterhelper.WithStart(e.start))
+	if err != nil {
+		return nil, err
+	}
+
+	return gelfexporter.NewLogs(
+		ctx,
+		set,
+		cfg,
+		&e.config.Config,
+		e.deadLetter,
+		e.pushLogs,
+		exporterhelper.WithStart(e.start),
+		exporterhelper.WithShutdown(e.shutdown),
+	)
 }
@@ -0,0 +1,33 @@
+// Package gelftest provides the fixtures, writers and GELF inputs shared by the tests of the GELF exporters.
+package gelftest
+
+import "go.opentelemetry.io/collector/pdata/plog"
+
+// NewBodyLogs returns logs with a log record for each body.
+func NewBodyLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+
+	for _, body := range bodies {
+		lr.AppendEmpty().Body().SetStr(body)
+	}
+
+	return ld
+}
+
+// LogBodies returns the bodies of the log records of the logs, in order.
+func LogBodies(ld plog.Logs) []string {
+	var bodies []string
+
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		for j := 0; j < ld.ResourceLogs().At(i).ScopeLogs().Len(); j++ {
+			lr := ld.ResourceLogs().At(i).ScopeLogs().At(j).LogRecords()
+
+			for k := 0; k < lr.Len(); k++ {
+				bodies = append(bodies, lr.At(k).Body().AsString())
+			}
+		}
+	}
+
+	return bodies
+}
@@ -0,0 +1,168 @@
+package gelftest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"math/big"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TCPServer is a GELF TCP input, using TLS if configured, counting the received messages.
+type TCPServer struct {
+	conns    []net.Conn
+	listener net.Listener
+	lock     sync.Mutex
+	received atomic.Int64
+}
+
+// StartTCPServer starts a GELF TCP input listening on the address, which is reset once the test finishes.
+func StartTCPServer(t *testing.T, address string, tlsConfig *tls.Config) *TCPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", address)
+	require.NoError(t, err)
+
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	s := &TCPServer{listener: listener}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			s.lock.Lock()
+			s.conns = append(s.conns, conn)
+			s.lock.Unlock()
+
+			go func() {
+				scanner := bufio.NewScanner(conn)
+				scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+					for i, b := range data {
+						if b == 0 {
+							return i + 1, data[:i], nil
+						}
+					}
+
+					return 0, nil, nil
+				})
+
+				for scanner.Scan() {
+					s.received.Add(1)
+				}
+			}()
+		}
+	}()
+
+	t.Cleanup(s.Reset)
+
+	return s
+}
+
+func (s *TCPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Received returns the number of messages received so far.
+func (s *TCPServer) Received() int64 {
+	return s.received.Load()
+}
+
+// Reset closes the listener and resets all accepted connections, so that writes to them fail.
+func (s *TCPServer) Reset() {
+	_ = s.listener.Close()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, conn := range s.conns {
+		if tlsConn, ok := conn.(*tls.Conn); ok {
+			conn = tlsConn.NetConn()
+		}
+
+		_ = conn.(*net.TCPConn).SetLinger(0)
+		_ = conn.Close()
+	}
+
+	s.conns = nil
+}
+
+// UDPServer is a GELF UDP input collecting the short messages it receives.
+type UDPServer struct {
+	lock     sync.Mutex
+	messages []string
+	reader   *gelf.Reader
+}
+
+// StartUDPServer starts a GELF UDP input listening on a free local port.
+func StartUDPServer(t *testing.T) *UDPServer {
+	t.Helper()
+
+	reader, err := gelf.NewReader("127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &UDPServer{reader: reader}
+
+	go func() {
+		for {
+			m, err := reader.ReadMessage()
+
+			if err != nil {
+				return
+			}
+
+			s.lock.Lock()
+			s.messages = append(s.messages, m.Short)
+			s.lock.Unlock()
+		}
+	}()
+
+	return s
+}
+
+func (s *UDPServer) Addr() string {
+	return s.reader.Addr()
+}
+
+// Messages returns the short messages received so far.
+func (s *UDPServer) Messages() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]string(nil), s.messages...)
+}
+
+// NewTLSConfig returns a TLS configuration with a self-signed certificate for 127.0.0.1.
+func NewTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotAfter:     time.Now().Add(time.Hour),
+		NotBefore:    time.Now(),
+		SerialNumber: big.NewInt(1),
+	}
+
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{certificate}, PrivateKey: key}}}
+}
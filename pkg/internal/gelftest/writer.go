@@ -0,0 +1,50 @@
+package gelftest
+
+import (
+	"errors"
+	"gopkg.in/Graylog2/go-gelf.v2/gelf"
+	"sync"
+)
+
+// FailingWriter records the short messages written to it, failing the messages whose short message is failing.
+type FailingWriter struct {
+	gelf.Writer
+	failing  map[string]bool
+	lock     sync.Mutex
+	messages []string
+}
+
+func NewFailingWriter(failing ...string) *FailingWriter {
+	w := &FailingWriter{failing: make(map[string]bool, len(failing))}
+
+	for _, short := range failing {
+		w.failing[short] = true
+	}
+
+	return w
+}
+
+func (w *FailingWriter) WriteMessage(m *gelf.Message) error {
+	if w.failing[m.Short] {
+		return errors.New("connection refused")
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.messages = append(w.messages, m.Short)
+
+	return nil
+}
+
+func (w *FailingWriter) Close() error {
+	return nil
+}
+
+// Messages returns the short messages written so far, in order.
+func (w *FailingWriter) Messages() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return append([]string(nil), w.messages...)
+}